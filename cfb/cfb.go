@@ -0,0 +1,86 @@
+// Package cfb reads the streams of the compound files, the container of the legacy binary office documents.
+package cfb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// Magic is the signature at the beginning of a compound file
+var Magic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+var (
+	// ErrNotFound is returned when a stream of the document is missing from the file
+	ErrNotFound = errors.New("stream not found")
+	// ErrEncrypted is returned when the document in the file is encrypted
+	ErrEncrypted = errors.New("document is encrypted")
+)
+
+// maxStreamSize is the largest stream read into memory
+const maxStreamSize = 512 << 20
+
+// IsCompound checks the signature at the head of the content
+func IsCompound(head []byte) bool {
+	return bytes.HasPrefix(head, Magic)
+}
+
+// Names returns the names of the streams and the storages in the root storage of the file
+func Names(r io.ReaderAt) (names []string, err error) {
+	defer recoverError(&err)
+	doc, err := mscfb.New(r)
+	if err != nil {
+		return nil, err
+	}
+	// the first entry is the root storage
+	for _, entry := range doc.File[min(len(doc.File), 1):] {
+		if len(entry.Path) == 0 && entry.Name != "" {
+			names = append(names, entry.Name)
+		}
+	}
+	return names, nil
+}
+
+// ReadStreams returns the content of the named streams in the root storage of the file,
+// the missing streams are not in the map.
+func ReadStreams(r io.ReaderAt, names ...string) (streams map[string][]byte, err error) {
+	defer recoverError(&err)
+	doc, err := mscfb.New(r)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	streams = make(map[string][]byte, len(names))
+	for _, entry := range doc.File {
+		if len(entry.Path) != 0 || !wanted[entry.Name] || entry.Size == 0 {
+			continue
+		}
+		if _, ok := streams[entry.Name]; ok {
+			continue
+		}
+		if entry.Size > maxStreamSize {
+			return nil, fmt.Errorf("stream %s is too large: %d bytes", entry.Name, entry.Size)
+		}
+		data := make([]byte, entry.Size)
+		n, err := io.ReadFull(entry, data)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("read stream %s: %w", entry.Name, err)
+		}
+		streams[entry.Name] = data[:n]
+	}
+	return streams, nil
+}
+
+// recoverError turns the panic on a malformed file into an error
+func recoverError(err *error) {
+	if v := recover(); v != nil {
+		*err = fmt.Errorf("malformed compound file: %v", v)
+	}
+}
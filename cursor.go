@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"iter"
+	"strings"
+	"sync"
+	"time"
+
+	"loader/filetype"
+	"loader/loaders"
+	"loader/schema"
+	"loader/textsplitter"
+)
+
+// cursorTTL is the idle time after which an open cursor is closed
+const cursorTTL = 10 * time.Minute
+
+// defaultLimit is the number of documents returned by next when the limit is not set
+const defaultLimit = 100
+
+// cursors are the cursors opened in the plugin process
+var cursors = &cursorStore{cursors: map[string]*cursor{}}
+
+// cursor returns the documents of a file in batches, the loaded documents are split as they are read.
+// The file is kept open for the loaders producing the documents one by one, so only the current batch
+// is kept in memory, it is closed once all the documents are read or the cursor is closed.
+type cursor struct {
+	mu       sync.Mutex
+	info     filetype.Info
+	splitter textsplitter.TextSplitter
+	pull     func() (schema.Document, error, bool)
+	release  func()
+	chunks   []schema.Document
+	eof      bool
+	used     time.Time
+	warnings *pageWarnings
+	// ctx and cancel are the context of the loader producing the documents one by one,
+	// timeout is the max time of each batch read from it
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	timeout time.Duration
+}
+
+// cursorStore keeps the open cursors by their handle
+type cursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]*cursor
+}
+
+// open returns the handle of a new cursor over the documents of the file. The files of the loaders
+// without LoadIter are loaded at once within the timeout, the timeout of the other loaders applies
+// to each batch read by open and next.
+func (s *cursorStore) open(ctx context.Context, path string, info filetype.Info, opts Options) (map[string]interface{}, error) {
+	if info.Type == filetype.DIR {
+		return nil, invalidArgument("%s is a directory", path)
+	}
+	splitter, err := newSplitter(info.Type, opts)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newCursorID()
+	if err != nil {
+		return nil, err
+	}
+	warnings := &pageWarnings{}
+	f, loader, err := openFile(path, info, opts, warnings.add)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &cursor{info: info, splitter: splitter, used: time.Now(), warnings: warnings}
+	if iterLoader, ok := loader.(loaders.IterLoader); ok {
+		c.timeout = time.Duration(opts.Timeout * float64(time.Second))
+		c.pullIter(iterLoader.LoadIter, func() { f.Close() })
+	} else {
+		docs, err := loader.Load(ctx)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		c.pull = pullSlice(docs)
+	}
+
+	if _, err := c.read(opts.Offset); err != nil {
+		c.close()
+		return nil, err
+	}
+
+	s.expire()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[id] = c
+	return map[string]interface{}{"cursor": id, "file_type": info.Type, "mime_type": info.MIME}, nil
+}
+
+// next returns the next batch of documents of the cursor, the cursor is closed when all the documents are read.
+// The pages skipped in the tolerant mode since the previous batch are reported in the warnings.
+func (s *cursorStore) next(handle interface{}, args []interface{}) (map[string]interface{}, error) {
+	id, c, err := s.get(handle)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseLimit(args)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	docs, err := c.read(limit)
+	done := err != nil || c.done()
+	if done {
+		c.close()
+	}
+	warnings := c.warnings.take()
+	c.mu.Unlock()
+
+	if done {
+		s.remove(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]interface{}{"documents": docs, "done": done}
+	if len(warnings) > 0 {
+		res["warnings"] = warnings
+	}
+	return res, nil
+}
+
+// close frees the cursor, closed reports whether the cursor was still open
+func (s *cursorStore) close(handle interface{}) (map[string]interface{}, error) {
+	id, ok := handle.(string)
+	if !ok {
+		return nil, invalidArgument("invalid cursor: %v", handle)
+	}
+	s.mu.Lock()
+	c, ok := s.cursors[id]
+	delete(s.cursors, id)
+	s.mu.Unlock()
+
+	if ok {
+		c.mu.Lock()
+		c.close()
+		c.mu.Unlock()
+	}
+	return map[string]interface{}{"closed": ok}, nil
+}
+
+// get returns the open cursor of the handle
+func (s *cursorStore) get(handle interface{}) (string, *cursor, error) {
+	id, ok := handle.(string)
+	if !ok {
+		return "", nil, invalidArgument("invalid cursor: %v", handle)
+	}
+	s.expire()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.cursors[id]
+	if !ok {
+		return "", nil, invalidArgument("cursor %s is closed or expired", id)
+	}
+	c.used = time.Now()
+	return id, c, nil
+}
+
+// remove deletes the cursor from the store
+func (s *cursorStore) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cursors, id)
+}
+
+// expire closes the cursors not used for cursorTTL
+func (s *cursorStore) expire() {
+	expired := []*cursor{}
+	s.mu.Lock()
+	for id, c := range s.cursors {
+		if time.Since(c.used) > cursorTTL {
+			expired = append(expired, c)
+			delete(s.cursors, id)
+		}
+	}
+	s.mu.Unlock()
+
+	// a cursor is locked after the store, close them without holding the store lock
+	for _, c := range expired {
+		c.mu.Lock()
+		c.close()
+		c.mu.Unlock()
+	}
+}
+
+// pullIter pulls the documents of the loader one by one. The loader outlives the call, it is canceled
+// when the cursor is released or when a batch is not read within the timeout.
+func (c *cursor) pullIter(load func(context.Context) iter.Seq2[schema.Document, error], release func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	pull, stop := iter.Pull2(load(ctx))
+	c.ctx = ctx
+	c.cancel = cancel
+	c.pull = pull
+	c.release = func() {
+		cancel(nil)
+		stop()
+		release()
+	}
+}
+
+// read returns up to limit split documents
+func (c *cursor) read(limit int) ([]schema.Document, error) {
+	if c.timeout > 0 && c.cancel != nil {
+		timer := time.AfterFunc(c.timeout, func() { c.cancel(context.DeadlineExceeded) })
+		defer timer.Stop()
+	}
+	// read one more document ahead so done is known after the last batch
+	if err := c.fill(limit + 1); err != nil {
+		return nil, err
+	}
+	limit = min(limit, len(c.chunks))
+	docs := c.chunks[:limit:limit]
+	c.chunks = c.chunks[limit:]
+	return docs, nil
+}
+
+// fill pulls and splits the loaded documents until n chunks are buffered or the documents are exhausted
+func (c *cursor) fill(n int) error {
+	for len(c.chunks) < n && !c.eof {
+		if c.pull == nil {
+			// the cursor is closed
+			c.eof = true
+			break
+		}
+		doc, err, ok := c.pull()
+		if !ok {
+			c.eof = true
+			break
+		}
+		if err != nil {
+			if c.ctx != nil && c.ctx.Err() != nil {
+				// the timeout of the batch is reported instead of the cancellation of the loader
+				return context.Cause(c.ctx)
+			}
+			return err
+		}
+
+		chunks := []schema.Document{doc}
+		if c.splitter != nil {
+			chunks, err = textsplitter.SplitDocuments(c.splitter, chunks)
+			if err != nil {
+				return err
+			}
+		}
+		for i := range chunks {
+			setFileType(&chunks[i], c.info)
+		}
+		c.chunks = append(c.chunks, chunks...)
+	}
+	return nil
+}
+
+// done reports whether all the documents are read
+func (c *cursor) done() bool {
+	return c.eof && len(c.chunks) == 0
+}
+
+// close releases the file of the cursor, c.mu must be held
+func (c *cursor) close() {
+	if c.release != nil {
+		c.release()
+	}
+	c.pull = nil
+	c.release = nil
+	c.chunks = nil
+}
+
+// pullSlice returns a pull function over the documents
+func pullSlice(docs []schema.Document) func() (schema.Document, error, bool) {
+	return func() (schema.Document, error, bool) {
+		if len(docs) == 0 {
+			return schema.Document{}, nil, false
+		}
+		doc := docs[0]
+		docs = docs[1:]
+		return doc, nil, true
+	}
+}
+
+// newCursorID returns a random cursor handle
+func newCursorID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// parseLimit returns the number of documents of the next method, given as a number or the limit option
+func parseLimit(args []interface{}) (int, error) {
+	if len(args) == 0 || args[0] == nil {
+		return defaultLimit, nil
+	}
+
+	limit, ok := toInt(args[0])
+	if !ok {
+		s, isString := args[0].(string)
+		if isString && !strings.HasPrefix(strings.TrimSpace(s), "{") {
+			return 0, invalidArgument("invalid limit: %s", s)
+		}
+		opts, err := parseOptions(args[:1])
+		if err != nil {
+			return 0, err
+		}
+		limit = opts.Limit
+	}
+	if limit < 0 {
+		return 0, invalidArgument("limit must not be negative")
+	}
+	if limit == 0 {
+		return defaultLimit, nil
+	}
+	return limit, nil
+}
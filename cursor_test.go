@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"iter"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"loader/filetype"
+	"loader/schema"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "people.csv")
+	require.NoError(t, os.WriteFile(path, []byte("name,age\nJohn,25\nJane,32\nJim,41\n"), 0o644))
+	info, err := filetype.Detect(path)
+	require.NoError(t, err)
+
+	res, err := cursors.open(context.Background(), path, info, Options{Splitter: splitterNone, Offset: 1})
+	require.NoError(t, err)
+	id := res["cursor"]
+
+	res, err = cursors.next(id, []interface{}{float64(1)})
+	require.NoError(t, err)
+	docs := res["documents"].([]schema.Document)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "name: Jane\nage: 32", docs[0].PageContent)
+	assert.Equal(t, filetype.CSV, docs[0].Metadata["file_type"])
+	assert.Equal(t, false, res["done"])
+
+	res, err = cursors.next(id, []interface{}{map[string]interface{}{"limit": float64(5)}})
+	require.NoError(t, err)
+	docs = res["documents"].([]schema.Document)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "name: Jim\nage: 41", docs[0].PageContent)
+	assert.Equal(t, true, res["done"])
+
+	// the cursor is closed after the last batch
+	_, err = cursors.next(id, nil)
+	require.ErrorIs(t, err, errInvalidArgument)
+	res, err = cursors.close(id)
+	require.NoError(t, err)
+	assert.Equal(t, false, res["closed"])
+}
+
+func TestCursorClose(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("some notes"), 0o644))
+	info, err := filetype.Detect(path)
+	require.NoError(t, err)
+
+	res, err := cursors.open(context.Background(), path, info, Options{})
+	require.NoError(t, err)
+	id := res["cursor"]
+
+	res, err = cursors.close(id)
+	require.NoError(t, err)
+	assert.Equal(t, true, res["closed"])
+	_, err = cursors.next(id, nil)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+func TestCursorTimeout(t *testing.T) {
+	t.Parallel()
+
+	// the loader returns the first document and then waits until it is canceled
+	load := func(ctx context.Context) iter.Seq2[schema.Document, error] {
+		return func(yield func(schema.Document, error) bool) {
+			if !yield(schema.Document{PageContent: "first"}, nil) {
+				return
+			}
+			<-ctx.Done()
+			yield(schema.Document{}, ctx.Err())
+		}
+	}
+	released := false
+	c := &cursor{timeout: 50 * time.Millisecond, warnings: &pageWarnings{}}
+	c.pullIter(load, func() { released = true })
+
+	_, err := c.read(1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	c.close()
+	assert.True(t, released)
+}
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	docs := []schema.Document{{PageContent: "1"}, {PageContent: "2"}, {PageContent: "3"}}
+	assert.Len(t, paginate(docs, Options{}), 3)
+	assert.Equal(t, []schema.Document{{PageContent: "2"}}, paginate(docs, Options{Offset: 1, Limit: 1}))
+	assert.Equal(t, []schema.Document{{PageContent: "3"}}, paginate(docs, Options{Offset: 2, Limit: 5}))
+	assert.Empty(t, paginate(docs, Options{Offset: 3}))
+}
+
+func TestLoadFileLimit(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "people.csv")
+	require.NoError(t, os.WriteFile(path, []byte("name,age\nJohn,25\nJane,32\nJim,41\n"), 0o644))
+	info, err := filetype.Detect(path)
+	require.NoError(t, err)
+
+	docs, err := loadFile(context.Background(), path, info, Options{Offset: 1, Limit: 1}, false)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "name: Jane\nage: 32", docs[0].PageContent)
+	assert.Equal(t, 2, docs[0].Metadata["row"])
+}
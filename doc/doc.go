@@ -0,0 +1,269 @@
+// Package doc reads the text of the Word 97-2003 binary documents.
+package doc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"loader/cfb"
+)
+
+// The streams of the document
+const (
+	wordStream   = "WordDocument"
+	table0Stream = "0Table"
+	table1Stream = "1Table"
+)
+
+const (
+	// wIdent is the identifier at the head of the file information block
+	wIdent = 0xA5EC
+	// fEncrypted and fWhichTblStm are the flags of the file information block
+	fEncrypted   = 0x0100
+	fWhichTblStm = 0x0200
+	// clxIndex is the index of fcClx and lcbClx in the fc/lcb pairs
+	clxIndex = 33
+	// fCompressed is set in the file offset of the pieces stored in 8-bit characters
+	fCompressed = 0x40000000
+)
+
+// The special characters of the text
+const (
+	cellMark       = 0x07
+	paragraphMark  = 0x0D
+	lineBreak      = 0x0B
+	pageBreak      = 0x0C
+	fieldBegin     = 0x13
+	fieldSeparator = 0x14
+	fieldEnd       = 0x15
+	nonBreakHyphen = 0x1E
+	optionalHyphen = 0x1F
+)
+
+// errFormat is returned when a structure of the document is malformed
+var errFormat = errors.New("malformed word document")
+
+// Read returns the paragraphs of the main text of the document, the cells of the tables
+// are separated by tabs.
+func Read(r io.ReaderAt) ([]string, error) {
+	streams, err := cfb.ReadStreams(r, wordStream, table0Stream, table1Stream)
+	if err != nil {
+		return nil, err
+	}
+	word := streams[wordStream]
+	if word == nil {
+		return nil, fmt.Errorf("%w: %s", cfb.ErrNotFound, wordStream)
+	}
+	fib, err := readFib(word)
+	if err != nil {
+		return nil, err
+	}
+	if fib.flags&fEncrypted != 0 {
+		return nil, cfb.ErrEncrypted
+	}
+	tableName := table0Stream
+	if fib.flags&fWhichTblStm != 0 {
+		tableName = table1Stream
+	}
+	table := streams[tableName]
+	if table == nil {
+		return nil, fmt.Errorf("%w: %s", cfb.ErrNotFound, tableName)
+	}
+
+	var text []uint16
+	if fib.lcbClx == 0 {
+		// without the piece table the text is stored after the file information block
+		text, err = readChars(word, fib.fcMin, fib.ccpText, false)
+	} else {
+		text, err = readPieces(word, table, fib)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return paragraphs(text), nil
+}
+
+// fib is the part of the file information block needed to read the text
+type fib struct {
+	flags   uint16
+	fcMin   uint32
+	ccpText uint32
+	fcClx   uint32
+	lcbClx  uint32
+}
+
+// readFib reads the file information block at the head of the WordDocument stream
+func readFib(word []byte) (fib, error) {
+	if len(word) < 34 || binary.LittleEndian.Uint16(word) != wIdent {
+		return fib{}, fmt.Errorf("%w: bad file information block", errFormat)
+	}
+	f := fib{
+		flags: binary.LittleEndian.Uint16(word[0x0A:]),
+		fcMin: binary.LittleEndian.Uint32(word[0x18:]),
+	}
+	csw := int(binary.LittleEndian.Uint16(word[32:]))
+	pos := 34 + csw*2
+	if len(word) < pos+2 {
+		return fib{}, fmt.Errorf("%w: bad file information block", errFormat)
+	}
+	cslw := int(binary.LittleEndian.Uint16(word[pos:]))
+	rgLw := pos + 2
+	pos = rgLw + cslw*4
+	if cslw < 4 || len(word) < pos+2 {
+		return fib{}, fmt.Errorf("%w: bad file information block", errFormat)
+	}
+	f.ccpText = binary.LittleEndian.Uint32(word[rgLw+3*4:])
+	cbRgFcLcb := int(binary.LittleEndian.Uint16(word[pos:]))
+	rgFcLcb := pos + 2
+	if cbRgFcLcb > clxIndex && len(word) >= rgFcLcb+(clxIndex+1)*8 {
+		f.fcClx = binary.LittleEndian.Uint32(word[rgFcLcb+clxIndex*8:])
+		f.lcbClx = binary.LittleEndian.Uint32(word[rgFcLcb+clxIndex*8+4:])
+	}
+	return f, nil
+}
+
+// readPieces reads the main text from the pieces of the piece table in the table stream
+func readPieces(word []byte, table []byte, f fib) ([]uint16, error) {
+	if uint64(f.fcClx)+uint64(f.lcbClx) > uint64(len(table)) {
+		return nil, fmt.Errorf("%w: bad piece table", errFormat)
+	}
+	clx := table[f.fcClx : f.fcClx+f.lcbClx]
+	// skip the property modifiers before the piece table
+	for len(clx) > 0 && clx[0] == 0x01 {
+		if len(clx) < 3 {
+			return nil, fmt.Errorf("%w: bad piece table", errFormat)
+		}
+		size := int(int16(binary.LittleEndian.Uint16(clx[1:])))
+		if size < 0 || len(clx) < 3+size {
+			return nil, fmt.Errorf("%w: bad piece table", errFormat)
+		}
+		clx = clx[3+size:]
+	}
+	if len(clx) < 5 || clx[0] != 0x02 {
+		return nil, fmt.Errorf("%w: bad piece table", errFormat)
+	}
+	lcb := binary.LittleEndian.Uint32(clx[1:])
+	plc := clx[5:]
+	if lcb < 4 || uint64(lcb) > uint64(len(plc)) || (lcb-4)%12 != 0 {
+		return nil, fmt.Errorf("%w: bad piece table", errFormat)
+	}
+	n := int(lcb-4) / 12
+	cps := plc[:(n+1)*4]
+	pcds := plc[(n+1)*4:]
+
+	// ccpText comes from the file, the text can not be longer than the stream
+	text := make([]uint16, 0, min(f.ccpText, uint32(len(word))))
+	for i := 0; i < n && uint32(len(text)) < f.ccpText; i++ {
+		start := binary.LittleEndian.Uint32(cps[i*4:])
+		end := binary.LittleEndian.Uint32(cps[(i+1)*4:])
+		if end <= start {
+			continue
+		}
+		count := min(end-start, f.ccpText-uint32(len(text)))
+		fc := binary.LittleEndian.Uint32(pcds[i*8+2:])
+		var chars []uint16
+		var err error
+		if fc&fCompressed != 0 {
+			chars, err = readChars(word, (fc&^fCompressed)/2, count, true)
+		} else {
+			chars, err = readChars(word, fc, count, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		text = append(text, chars...)
+	}
+	return text, nil
+}
+
+// readChars reads count characters at the offset of the stream, the compressed characters are 8-bit
+func readChars(word []byte, offset uint32, count uint32, compressed bool) ([]uint16, error) {
+	size := uint64(count) * 2
+	if compressed {
+		size = uint64(count)
+	}
+	if uint64(offset)+size > uint64(len(word)) {
+		return nil, fmt.Errorf("%w: text out of the stream", errFormat)
+	}
+	chars := make([]uint16, count)
+	for i := range chars {
+		if compressed {
+			chars[i] = uint16(cp1252(word[offset+uint32(i)]))
+		} else {
+			chars[i] = binary.LittleEndian.Uint16(word[offset+uint32(i)*2:])
+		}
+	}
+	return chars, nil
+}
+
+// paragraphs splits the text at the paragraph marks, the codes of the fields and the
+// special characters of the objects are removed.
+func paragraphs(text []uint16) []string {
+	var (
+		result  []string
+		current []uint16
+		// fields are the fields being read, true when the code of the field is being read
+		fields []bool
+	)
+	flush := func() {
+		result = append(result, strings.TrimRight(string(utf16.Decode(current)), "\t"))
+		current = current[:0]
+	}
+	for _, c := range text {
+		switch c {
+		case fieldBegin:
+			fields = append(fields, true)
+			continue
+		case fieldSeparator:
+			if len(fields) > 0 {
+				fields[len(fields)-1] = false
+			}
+			continue
+		case fieldEnd:
+			if len(fields) > 0 {
+				fields = fields[:len(fields)-1]
+			}
+			continue
+		}
+		if len(fields) > 0 && fields[len(fields)-1] {
+			continue
+		}
+
+		switch {
+		case c == paragraphMark || c == pageBreak:
+			flush()
+		case c == cellMark:
+			// the marks of the cells and of the ends of the rows are told apart by the paragraph
+			// properties, which are not read, so the cells of a table are on a single line
+			current = append(current, '\t')
+		case c == lineBreak:
+			current = append(current, '\n')
+		case c == nonBreakHyphen:
+			current = append(current, '-')
+		case c == '\t' || c >= 0x20:
+			current = append(current, c)
+		}
+	}
+	if len(current) > 0 {
+		flush()
+	}
+	return result
+}
+
+// cp1252Chars are the characters of the bytes 0x80-0x9F in the windows-1252 code page
+var cp1252Chars = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// cp1252 returns the character of the windows-1252 byte
+func cp1252(b byte) rune {
+	if b >= 0x80 && b < 0xA0 {
+		return cp1252Chars[b-0x80]
+	}
+	return rune(b)
+}
@@ -3,46 +3,412 @@ package docx
 import (
 	"archive/zip"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"io"
+	"path"
+	"strings"
 )
 
+// maxPartSize is the limit of the uncompressed size of a file of the document
+const maxPartSize = 1 << 30
+
+// ErrTooLarge is returned when a file of the document is larger than maxPartSize
+var ErrTooLarge = errors.New("document part is too large")
+
 type Document struct {
 	Body Body `xml:"body"`
 }
 
+// Body is the content of the document, it is decoded by UnmarshalXML
 type Body struct {
 	Paragraphs []Paragraph `xml:"p"`
+	Tables     []Table     `xml:"tbl"`
+	// Blocks are the paragraphs and the tables in document order
+	Blocks []Block `xml:"-"`
+}
+
+// UnmarshalXML decodes the paragraphs and the tables of the body in document order,
+// the paragraphs and the tables of the content controls are included.
+func (b *Body) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	blocks, err := decodeBlocks(d, nil)
+	if err != nil {
+		return err
+	}
+	*b = Body{Paragraphs: []Paragraph{}, Tables: []Table{}, Blocks: blocks}
+	for _, block := range blocks {
+		if block.Paragraph != nil {
+			b.Paragraphs = append(b.Paragraphs, *block.Paragraph)
+		} else {
+			b.Tables = append(b.Tables, *block.Table)
+		}
+	}
+	return nil
 }
 
+// Paragraph is a paragraph of the document, it is decoded by UnmarshalXML
 type Paragraph struct {
 	Texts                 []Text `xml:"r>t"`
 	LastRenderedPageBreak []bool `xml:"r>lastRenderedPageBreak"`
 	Hyperlink             []Text `xml:"hyperlink>r>t"`
+	// Inline, Anchor and Legacy are the pictures of the paragraph, see Images
+	Inline []Image `xml:"r>drawing>inline>graphic>graphicData>pic>blipFill>blip"`
+	Anchor []Image `xml:"r>drawing>anchor>graphic>graphicData>pic>blipFill>blip"`
+	Legacy []Image `xml:"r>pict>shape>imagedata"`
+
+	// Properties are the style, the numbering and the outline level of the paragraph
+	Properties ParagraphProperties `xml:"pPr"`
+	// Content is all the texts of the paragraph in document order, the texts of the runs, of the links
+	// and of the other containers, the tabs and the line breaks are texts too
+	Content []Text `xml:"-"`
 }
 
 type Text struct {
 	Content string `xml:",chardata"`
+	// Reference is set on the empty texts of Paragraph.Content marking the references to the notes
+	// and the ranges of the comments
+	Reference *Reference `xml:"-"`
+	// Revision is set on the texts of Paragraph.Content inserted or deleted while the changes were tracked
+	Revision string `xml:"-"`
+	// PageBreak is set on the texts of Paragraph.Content marking a page break, explicit or rendered by Word
+	PageBreak bool `xml:"-"`
+}
+
+// The revisions of the tracked changes
+const (
+	RevisionInserted = "inserted"
+	RevisionDeleted  = "deleted"
+)
+
+// revisionElements are the revisions of the texts by the name of the element tracking the change
+var revisionElements = map[string]string{
+	"ins":      RevisionInserted,
+	"moveTo":   RevisionInserted,
+	"del":      RevisionDeleted,
+	"moveFrom": RevisionDeleted,
+}
+
+// The kinds of the references of the paragraphs
+const (
+	ReferenceFootnote     = "footnote"
+	ReferenceEndnote      = "endnote"
+	ReferenceCommentStart = "comment_start"
+	ReferenceCommentEnd   = "comment_end"
+)
+
+// Reference is a reference to a note or the start or the end of the range of a comment,
+// ID is the id of the note or of the comment, see ReadParts
+type Reference struct {
+	Kind string
+	ID   string
+}
+
+// referenceKinds are the kinds of the references by element name
+var referenceKinds = map[string]string{
+	"footnoteReference": ReferenceFootnote,
+	"endnoteReference":  ReferenceEndnote,
+	"commentRangeStart": ReferenceCommentStart,
+	"commentRangeEnd":   ReferenceCommentEnd,
+}
+
+// ParagraphProperties are the properties of a paragraph or of a paragraph style
+type ParagraphProperties struct {
+	Style        Value                `xml:"pStyle"`
+	Numbering    *NumberingProperties `xml:"numPr"`
+	OutlineLevel *Value               `xml:"outlineLvl"`
+	// PageBreakBefore starts the paragraph on a new page, it is off when its value is "0" or "false"
+	PageBreakBefore *Value `xml:"pageBreakBefore"`
+}
+
+// NumberingProperties are the list of the paragraph, ID is the numbering and Level the 0 based list level
+type NumberingProperties struct {
+	Level Value `xml:"ilvl"`
+	ID    Value `xml:"numId"`
+}
+
+// Value is an element whose value is its val attribute
+type Value struct {
+	Val string `xml:"val,attr"`
+}
+
+// The paths of the elements of the paragraph decoded into the fields of Paragraph
+const (
+	runTextPath     = "r>t"
+	linkTextPath    = "hyperlink>r>t"
+	pageBreakPath   = "r>lastRenderedPageBreak"
+	inlineImagePath = "r>drawing>inline>graphic>graphicData>pic>blipFill>blip"
+	anchorImagePath = "r>drawing>anchor>graphic>graphicData>pic>blipFill>blip"
+	legacyImagePath = "r>pict>shape>imagedata"
+	propertiesPath  = "pPr"
+)
+
+// UnmarshalXML decodes the paragraph, the fields are filled as their tags tell
+// and the texts are collected in document order into Content.
+func (p *Paragraph) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = Paragraph{}
+	stack := []string{}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, token.Name.Local)
+			path := strings.Join(stack, ">")
+			parent := ""
+			if len(stack) > 1 {
+				parent = stack[len(stack)-2]
+			}
+
+			decoded := true
+			switch {
+			case path == propertiesPath:
+				err = d.DecodeElement(&p.Properties, &token)
+			case token.Name.Local == "t", token.Name.Local == "delText":
+				var text Text
+				err = d.DecodeElement(&text, &token)
+				text.Revision = revision(stack)
+				switch path {
+				case runTextPath:
+					p.Texts = append(p.Texts, text)
+				case linkTextPath:
+					p.Hyperlink = append(p.Hyperlink, text)
+				}
+				p.Content = append(p.Content, text)
+			case path == inlineImagePath, path == anchorImagePath, path == legacyImagePath:
+				var image Image
+				err = d.DecodeElement(&image, &token)
+				switch path {
+				case inlineImagePath:
+					p.Inline = append(p.Inline, image)
+				case anchorImagePath:
+					p.Anchor = append(p.Anchor, image)
+				default:
+					p.Legacy = append(p.Legacy, image)
+				}
+			default:
+				decoded = false
+				switch {
+				case token.Name.Local == "lastRenderedPageBreak":
+					if path == pageBreakPath {
+						p.LastRenderedPageBreak = append(p.LastRenderedPageBreak, true)
+					}
+					p.Content = append(p.Content, Text{PageBreak: true})
+				case parent == "r" && token.Name.Local == "tab":
+					p.Content = append(p.Content, Text{Content: "\t", Revision: revision(stack)})
+				case parent == "r" && (token.Name.Local == "br" || token.Name.Local == "cr"):
+					pageBreak := token.Name.Local == "br" && attr(token, "type") == "page"
+					p.Content = append(p.Content, Text{Content: "\n", Revision: revision(stack), PageBreak: pageBreak})
+				case referenceKinds[token.Name.Local] != "":
+					reference := &Reference{Kind: referenceKinds[token.Name.Local], ID: attr(token, "id")}
+					p.Content = append(p.Content, Text{Reference: reference})
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if decoded {
+				// the end of the element is read by DecodeElement
+				stack = stack[:len(stack)-1]
+			}
+
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// revision returns the revision of the texts of the innermost element tracking a change in the stack
+func revision(stack []string) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if revision, ok := revisionElements[stack[i]]; ok {
+			return revision
+		}
+	}
+	return ""
+}
+
+// References returns the references of the paragraph to the notes and the comments
+func (p Paragraph) References() []Reference {
+	references := []Reference{}
+	for _, t := range p.Content {
+		if t.Reference != nil {
+			references = append(references, *t.Reference)
+		}
+	}
+	return references
+}
+
+// attr returns the value of the attribute of the element by local name
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Text returns the content of the paragraph with the tracked changes accepted, the deleted texts are left out
+func (p Paragraph) Text() string {
+	var sb strings.Builder
+	for _, t := range p.Content {
+		if t.Revision != RevisionDeleted {
+			sb.WriteString(t.Content)
+		}
+	}
+	return sb.String()
+}
+
+// Image is a picture of the paragraph, Embed or ID is the relationship id of the image file
+type Image struct {
+	Embed string `xml:"embed,attr"`
+	ID    string `xml:"id,attr"`
+}
+
+// Images returns the relationship ids of the pictures of the paragraph, see ReadMedia
+func (p Paragraph) Images() []string {
+	ids := []string{}
+	for _, images := range [][]Image{p.Inline, p.Anchor, p.Legacy} {
+		for _, image := range images {
+			if image.Embed != "" {
+				ids = append(ids, image.Embed)
+			} else if image.ID != "" {
+				ids = append(ids, image.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// Media is an image file of the document
+type Media struct {
+	Name string
+	Data []byte
+}
+
+type relationships struct {
+	Relationships []relationship `xml:"Relationship"`
+}
+
+type relationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
 }
 
+// ReadMedia returns the image files of the document by relationship id
+func ReadMedia(r io.ReaderAt, size int64) (map[string]Media, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*zip.File{}
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+
+	media := map[string]Media{}
+	rels, err := readRelationships(files)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range rels {
+		if !strings.HasSuffix(rel.Type, "/image") || rel.TargetMode == "External" {
+			continue
+		}
+		name := rel.targetName()
+		file, ok := files[name]
+		if !ok {
+			continue
+		}
+		data, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		media[rel.ID] = Media{Name: name, Data: data}
+	}
+	return media, nil
+}
+
+// readRelationships returns the relationships of the main part of the document
+func readRelationships(files map[string]*zip.File) ([]relationship, error) {
+	rels, ok := files["word/_rels/document.xml.rels"]
+	if !ok {
+		return nil, nil
+	}
+	var doc relationships
+	if err := decodeFile(rels, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Relationships, nil
+}
+
+// targetName returns the name of the file of the zip archive targeted by the relationship of the main part
+func (rel relationship) targetName() string {
+	if strings.HasPrefix(rel.Target, "/") {
+		return strings.TrimPrefix(rel.Target, "/")
+	}
+	return path.Join("word", rel.Target)
+}
+
+// readFile returns the content of the file of the zip archive, up to maxPartSize bytes
+func readFile(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > maxPartSize {
+		return nil, fmt.Errorf("%w: %s", ErrTooLarge, file.Name)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxPartSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPartSize {
+		return nil, fmt.Errorf("%w: %s", ErrTooLarge, file.Name)
+	}
+	return data, nil
+}
+
+// Read returns the paragraphs of the document, the paragraphs of the tables are left out, see ReadBlocks
 func Read(r io.ReaderAt, size int64) ([]Paragraph, error) {
+	doc, err := readDocument(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Body.Paragraphs, nil
+}
+
+// ReadBlocks returns the paragraphs and the tables of the document in document order
+func ReadBlocks(r io.ReaderAt, size int64) ([]Block, error) {
+	doc, err := readDocument(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Body.Blocks, nil
+}
+
+// readDocument decodes the main part of the document
+func readDocument(r io.ReaderAt, size int64) (Document, error) {
 	// Open the .docx file
 	zipReader, err := zip.NewReader(r, size)
 	if err != nil {
-		return nil, err
+		return Document{}, err
 	}
 	// Find the document.xml file and read its content
 	var documentXML []byte
 	for _, file := range zipReader.File {
 		if file.Name == "word/document.xml" {
-			f, err := file.Open()
+			documentXML, err = readFile(file)
 			if err != nil {
-				return nil, err
-			}
-
-			documentXML, err = io.ReadAll(f)
-			f.Close()
-			if err != nil {
-				return nil, err
+				return Document{}, err
 			}
 			break
 		}
@@ -50,8 +416,5 @@ func Read(r io.ReaderAt, size int64) ([]Paragraph, error) {
 	// Unmarshal the XML content into a Document struct
 	var doc Document
 	err = xml.Unmarshal(documentXML, &doc)
-	if err != nil {
-		return nil, err
-	}
-	return doc.Body.Paragraphs, nil
+	return doc, err
 }
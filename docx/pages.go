@@ -0,0 +1,107 @@
+package docx
+
+import "strings"
+
+// EachParagraph calls f with the paragraphs of the blocks in document order, the paragraphs of the tables included
+func EachParagraph(blocks []Block, f func(p *Paragraph)) {
+	for _, block := range blocks {
+		if block.Paragraph != nil {
+			f(block.Paragraph)
+			continue
+		}
+		for _, row := range block.Table.Rows {
+			for _, cell := range row.Cells {
+				EachParagraph(cell.Blocks, f)
+			}
+		}
+	}
+}
+
+// Pages groups the blocks into approximate pages. The pages start at the explicit page breaks, at the paragraphs
+// starting on a new page and at the page breaks rendered by Word when the document was last saved.
+// A paragraph is split at its page breaks, the texts of the parts replace their Texts and their Hyperlink,
+// the pictures are kept on the first part. A table is kept on the page it starts on, the pages it spans
+// start after it. The page breaks of the pages without text are ignored, so the pages are not empty.
+func Pages(blocks []Block) [][]Block {
+	pages := [][]Block{}
+	page := []Block{}
+	hasText := false
+	newPage := func() {
+		if hasText {
+			pages = append(pages, page)
+			page = []Block{}
+			hasText = false
+		}
+	}
+
+	for _, block := range blocks {
+		if block.Table != nil {
+			page = append(page, block)
+			hasText = true
+			breaks := 0
+			EachParagraph([]Block{block}, func(p *Paragraph) {
+				for _, t := range p.Content {
+					if t.PageBreak {
+						breaks++
+					}
+				}
+			})
+			if breaks > 0 {
+				newPage()
+			}
+			continue
+		}
+
+		p := block.Paragraph
+		if before := p.Properties.PageBreakBefore; before != nil && before.Val != "0" && before.Val != "false" {
+			newPage()
+		}
+		parts := splitParagraph(*p)
+		for i, part := range parts {
+			if i > 0 {
+				newPage()
+			}
+			page = append(page, Block{Paragraph: part})
+			hasText = hasText || strings.TrimSpace(part.Text()) != "" || len(part.Images()) > 0
+		}
+	}
+	if len(page) > 0 {
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// splitParagraph splits the paragraph at its page breaks, it is returned as it is when it has none
+func splitParagraph(p Paragraph) []*Paragraph {
+	breaks := false
+	for _, t := range p.Content {
+		breaks = breaks || t.PageBreak
+	}
+	if !breaks {
+		return []*Paragraph{&p}
+	}
+
+	parts := []*Paragraph{}
+	content := []Text{}
+	addPart := func() {
+		part := Paragraph{Properties: p.Properties, Content: content}
+		if len(parts) == 0 {
+			part.Inline, part.Anchor, part.Legacy = p.Inline, p.Anchor, p.Legacy
+		}
+		if text := part.Text(); text != "" {
+			part.Texts = []Text{{Content: text}}
+		}
+		parts = append(parts, &part)
+		content = []Text{}
+	}
+	for _, t := range p.Content {
+		if t.PageBreak {
+			addPart()
+			// the line break of the explicit page breaks is not kept
+			continue
+		}
+		content = append(content, t)
+	}
+	addPart()
+	return parts
+}
@@ -0,0 +1,134 @@
+package docx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parts are the parts of the document apart from the body, see ReadParts
+type Parts struct {
+	Headers   []Part
+	Footers   []Part
+	Footnotes map[string]Note
+	Endnotes  map[string]Note
+	Comments  []Comment
+}
+
+// Part is a header or a footer of the document, it is decoded by UnmarshalXML
+type Part struct {
+	Name   string
+	Blocks []Block
+}
+
+// UnmarshalXML decodes the paragraphs and the tables of the part
+func (p *Part) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	blocks, err := decodeBlocks(d, nil)
+	p.Blocks = blocks
+	return err
+}
+
+// Note is a footnote or an endnote, the separators of the notes have a type and are left out
+type Note struct {
+	ID         string      `xml:"id,attr"`
+	Type       string      `xml:"type,attr"`
+	Paragraphs []Paragraph `xml:"p"`
+}
+
+// Comment is a comment of a reviewer, the commented text is marked by the references of the paragraphs
+type Comment struct {
+	ID         string      `xml:"id,attr"`
+	Author     string      `xml:"author,attr"`
+	Date       string      `xml:"date,attr"`
+	Paragraphs []Paragraph `xml:"p"`
+}
+
+type notesDocument struct {
+	Notes []Note `xml:",any"`
+}
+
+type commentsDocument struct {
+	Comments []Comment `xml:"comment"`
+}
+
+// ReadParts returns the headers, the footers, the footnotes, the endnotes and the comments of the document.
+// The headers and the footers are sorted by the number of their file, e.g. word/header2.xml.
+func ReadParts(r io.ReaderAt, size int64) (Parts, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return Parts{}, err
+	}
+	parts := Parts{Footnotes: map[string]Note{}, Endnotes: map[string]Note{}}
+	for _, file := range zipReader.File {
+		dir, name := path.Split(file.Name)
+		if dir != "word/" {
+			continue
+		}
+		switch {
+		case isNumberedPart(name, "header"), isNumberedPart(name, "footer"):
+			var part Part
+			if err := decodeFile(file, &part); err != nil {
+				return Parts{}, err
+			}
+			part.Name = file.Name
+			if strings.HasPrefix(name, "header") {
+				parts.Headers = append(parts.Headers, part)
+			} else {
+				parts.Footers = append(parts.Footers, part)
+			}
+		case name == "footnotes.xml", name == "endnotes.xml":
+			var doc notesDocument
+			if err := decodeFile(file, &doc); err != nil {
+				return Parts{}, err
+			}
+			notes := parts.Footnotes
+			if name == "endnotes.xml" {
+				notes = parts.Endnotes
+			}
+			for _, note := range doc.Notes {
+				if note.Type == "" || note.Type == "normal" {
+					notes[note.ID] = note
+				}
+			}
+		case name == "comments.xml":
+			var doc commentsDocument
+			if err := decodeFile(file, &doc); err != nil {
+				return Parts{}, err
+			}
+			parts.Comments = doc.Comments
+		}
+	}
+	sortParts(parts.Headers)
+	sortParts(parts.Footers)
+	return parts, nil
+}
+
+// isNumberedPart reports whether the file name is the prefix followed by a number, e.g. header1.xml
+func isNumberedPart(name string, prefix string) bool {
+	number, ok := strings.CutSuffix(strings.TrimPrefix(name, prefix), ".xml")
+	_, err := strconv.Atoi(number)
+	return ok && strings.HasPrefix(name, prefix) && err == nil
+}
+
+// sortParts sorts the parts by the number of their file
+func sortParts(parts []Part) {
+	number := func(part Part) int {
+		name := strings.TrimSuffix(path.Base(part.Name), ".xml")
+		n, _ := strconv.Atoi(strings.TrimLeft(name, "abcdefghijklmnopqrstuvwxyz"))
+		return n
+	}
+	sort.SliceStable(parts, func(i, j int) bool { return number(parts[i]) < number(parts[j]) })
+}
+
+// decodeFile decodes the xml file of the zip archive
+func decodeFile(file *zip.File, v any) error {
+	data, err := readFile(file)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(data, v)
+}
@@ -0,0 +1,123 @@
+package docx
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Properties are the core properties and the extended properties of the document,
+// the dates are in the W3CDTF format, e.g. "2024-03-01T10:00:00Z"
+type Properties struct {
+	Title          string `xml:"title"`
+	Subject        string `xml:"subject"`
+	Creator        string `xml:"creator"`
+	Keywords       string `xml:"keywords"`
+	LastModifiedBy string `xml:"lastModifiedBy"`
+	Revision       string `xml:"revision"`
+	Created        string `xml:"created"`
+	Modified       string `xml:"modified"`
+
+	// Words is counted by the application that last saved the document
+	Words int `xml:"-"`
+}
+
+type appProperties struct {
+	Words int `xml:"Words"`
+}
+
+// ReadProperties returns the properties of docProps/core.xml and docProps/app.xml,
+// the properties of the missing parts are empty
+func ReadProperties(r io.ReaderAt, size int64) (Properties, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return Properties{}, err
+	}
+	var properties Properties
+	for _, file := range zipReader.File {
+		switch file.Name {
+		case "docProps/core.xml":
+			if err := decodeFile(file, &properties); err != nil {
+				return Properties{}, err
+			}
+		case "docProps/app.xml":
+			var app appProperties
+			if err := decodeFile(file, &app); err != nil {
+				return Properties{}, err
+			}
+			properties.Words = app.Words
+		}
+	}
+	return properties, nil
+}
+
+// The types of the embedded files
+const (
+	EmbeddedImage = "image"
+	// EmbeddedObject is an OLE object, e.g. a workbook of an older Excel version
+	EmbeddedObject = "object"
+	// EmbeddedPackage is an Office Open XML file, e.g. a xlsx workbook
+	EmbeddedPackage = "package"
+)
+
+// embeddedTypes are the types of the embedded files by the suffix of their relationship type
+var embeddedTypes = map[string]string{
+	"/image":     EmbeddedImage,
+	"/oleObject": EmbeddedObject,
+	"/package":   EmbeddedPackage,
+}
+
+// Embedded is an image or an object embedded in the body of the document,
+// Name is the name of its file in the document, see ReadPart
+type Embedded struct {
+	ID   string
+	Type string
+	Name string
+}
+
+// ReadEmbedded returns the images and the objects embedded in the body of the document sorted by name,
+// the linked files are left out
+func ReadEmbedded(r io.ReaderAt, size int64) ([]Embedded, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*zip.File{}
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+	rels, err := readRelationships(files)
+	if err != nil {
+		return nil, err
+	}
+
+	embedded := []Embedded{}
+	for _, rel := range rels {
+		if rel.TargetMode == "External" {
+			continue
+		}
+		for suffix, kind := range embeddedTypes {
+			if _, ok := files[rel.targetName()]; ok && strings.HasSuffix(rel.Type, suffix) {
+				embedded = append(embedded, Embedded{ID: rel.ID, Type: kind, Name: rel.targetName()})
+			}
+		}
+	}
+	sort.Slice(embedded, func(i, j int) bool { return embedded[i].Name < embedded[j].Name })
+	return embedded, nil
+}
+
+// ReadPart returns the content of the file of the document, e.g. an embedded workbook
+func ReadPart(r io.ReaderAt, size int64, name string) ([]byte, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range zipReader.File {
+		if file.Name == name {
+			return readFile(file)
+		}
+	}
+	return nil, fmt.Errorf("%s not found", name)
+}
@@ -0,0 +1,129 @@
+package docx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxStyleDepth limits the chain of the base styles, malformed files may have loops
+const maxStyleDepth = 16
+
+// Style is a paragraph style of the document
+type Style struct {
+	ID         string              `xml:"styleId,attr"`
+	Type       string              `xml:"type,attr"`
+	Name       Value               `xml:"name"`
+	BasedOn    Value               `xml:"basedOn"`
+	Properties ParagraphProperties `xml:"pPr"`
+}
+
+// Styles are the paragraph styles of the document by style id
+type Styles map[string]Style
+
+type stylesDocument struct {
+	Styles []Style `xml:"style"`
+}
+
+// ReadStyles returns the paragraph styles of the document, they are empty when the document has no styles part
+func ReadStyles(r io.ReaderAt, size int64) (Styles, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	styles := Styles{}
+	for _, file := range zipReader.File {
+		if file.Name != "word/styles.xml" {
+			continue
+		}
+		data, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var doc stylesDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for _, style := range doc.Styles {
+			if style.Type == "" || style.Type == "paragraph" {
+				styles[style.ID] = style
+			}
+		}
+		break
+	}
+	return styles, nil
+}
+
+// HeadingLevel returns the 1 based heading level of the paragraph, zero when it is not a heading.
+// The level is the outline level of the paragraph or of its style, the "Title" and "heading N" styles
+// are headings too, the style is also recognized by its id when the document has no styles part.
+func (s Styles) HeadingLevel(p Paragraph) int {
+	if level, ok := outlineLevel(p.Properties.OutlineLevel); ok {
+		return level
+	}
+	id := p.Properties.Style.Val
+	for depth := 0; id != "" && depth < maxStyleDepth; depth++ {
+		style, ok := s[id]
+		name := style.Name.Val
+		if !ok {
+			name = id
+		}
+		if level, ok := outlineLevel(style.Properties.OutlineLevel); ok {
+			return level
+		}
+		if level := headingStyleLevel(name); level > 0 {
+			return level
+		}
+		id = style.BasedOn.Val
+	}
+	return 0
+}
+
+// ListLevel returns the 0 based list level of the paragraph, ok is false when the paragraph is not
+// an item of a list. The numbering is set on the paragraph or on its style.
+func (s Styles) ListLevel(p Paragraph) (level int, ok bool) {
+	numbering := p.Properties.Numbering
+	id := p.Properties.Style.Val
+	for depth := 0; numbering == nil && id != "" && depth < maxStyleDepth; depth++ {
+		style := s[id]
+		numbering = style.Properties.Numbering
+		id = style.BasedOn.Val
+	}
+	// the numbering id 0 removes the numbering of the style
+	if numbering == nil || numbering.ID.Val == "0" {
+		return 0, false
+	}
+	level, _ = strconv.Atoi(numbering.Level.Val)
+	return max(level, 0), true
+}
+
+// outlineLevel returns the 1 based heading level of the outline level, ok is false when it is not set.
+// The outline level 9 is the body text, it is not a heading.
+func outlineLevel(v *Value) (level int, ok bool) {
+	if v == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v.Val)
+	if err != nil || n < 0 || n > 9 {
+		return 0, false
+	}
+	if n == 9 {
+		return 0, true
+	}
+	return n + 1, true
+}
+
+// headingStyleLevel returns the level of the built-in heading styles from their name or id,
+// e.g. "heading 2" or "Heading2", the title is a first level heading
+func headingStyleLevel(name string) int {
+	name = strings.ToLower(strings.ReplaceAll(name, " ", ""))
+	if name == "title" {
+		return 1
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(name, "heading")); err == nil && strings.HasPrefix(name, "heading") && n >= 1 && n <= 9 {
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,160 @@
+package docx
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// maxGridColumns limits the columns of the grid of a table, the spans of malformed files may be huge
+const maxGridColumns = 1000
+
+// Block is a paragraph or a table of the document, one of them is set
+type Block struct {
+	Paragraph *Paragraph
+	Table     *Table
+}
+
+// Table is a table of the document
+type Table struct {
+	Rows []Row `xml:"tr"`
+}
+
+// Row is a row of a table, GridBefore is the number of grid columns skipped before the first cell
+type Row struct {
+	Properties RowProperties `xml:"trPr"`
+	Cells      []Cell        `xml:"tc"`
+}
+
+// RowProperties are the properties of a row
+type RowProperties struct {
+	GridBefore Value `xml:"gridBefore"`
+}
+
+// Cell is a cell of a table, it is decoded by UnmarshalXML
+type Cell struct {
+	Properties CellProperties `xml:"tcPr"`
+	// Blocks are the paragraphs and the nested tables of the cell in document order
+	Blocks []Block `xml:"-"`
+}
+
+// CellProperties are the merges of a cell. GridSpan is the number of grid columns of the cell,
+// VMerge is set on the cells merged with the cell above, its value is "restart" on the first cell of the merge.
+type CellProperties struct {
+	GridSpan Value  `xml:"gridSpan"`
+	VMerge   *Value `xml:"vMerge"`
+}
+
+// UnmarshalXML decodes the properties and the blocks of the cell
+func (c *Cell) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*c = Cell{}
+	blocks, err := decodeBlocks(d, func(start xml.StartElement) error {
+		if start.Name.Local == "tcPr" {
+			return d.DecodeElement(&c.Properties, &start)
+		}
+		return d.Skip()
+	})
+	c.Blocks = blocks
+	return err
+}
+
+// Paragraphs returns the paragraphs of the table, the paragraphs of the nested tables included
+func (t Table) Paragraphs() []Paragraph {
+	paragraphs := []Paragraph{}
+	for _, row := range t.Rows {
+		for _, cell := range row.Cells {
+			for _, block := range cell.Blocks {
+				if block.Paragraph != nil {
+					paragraphs = append(paragraphs, *block.Paragraph)
+				} else {
+					paragraphs = append(paragraphs, block.Table.Paragraphs()...)
+				}
+			}
+		}
+	}
+	return paragraphs
+}
+
+// Grid returns the cells of the table by row and grid column. A cell spanning several columns is repeated
+// in each of them and a cell merged with the cells below is repeated in their rows, the columns skipped
+// before the first cell of a row are nil. The rows may have different lengths.
+func (t Table) Grid() [][]*Cell {
+	grid := make([][]*Cell, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		before, _ := strconv.Atoi(row.Properties.GridBefore.Val)
+		cells := make([]*Cell, min(max(before, 0), maxGridColumns))
+		for i := range row.Cells {
+			cell := &row.Cells[i]
+			col := len(cells)
+			if merge := cell.Properties.VMerge; merge != nil && merge.Val != "restart" && len(grid) > 0 {
+				if above := grid[len(grid)-1]; col < len(above) && above[col] != nil {
+					cell = above[col]
+				}
+			}
+			span, _ := strconv.Atoi(row.Cells[i].Properties.GridSpan.Val)
+			for j := 0; j < max(span, 1) && len(cells) < maxGridColumns; j++ {
+				cells = append(cells, cell)
+			}
+		}
+		grid = append(grid, cells)
+	}
+	return grid
+}
+
+// sdtProperties are the properties of a content control, the gallery of the document parts
+// is "Table of Contents" for the tables of contents
+type sdtProperties struct {
+	Gallery Value `xml:"docPartObj>docPartGallery"`
+}
+
+// decodeBlocks decodes the paragraphs and the tables until the end of the element being decoded.
+// The blocks of the content controls are included except the tables of contents, the other elements
+// are passed to other, they are skipped when other is nil.
+func decodeBlocks(d *xml.Decoder, other func(start xml.StartElement) error) ([]Block, error) {
+	blocks := []Block{}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "p":
+				p := &Paragraph{}
+				err = d.DecodeElement(p, &token)
+				blocks = append(blocks, Block{Paragraph: p})
+			case "tbl":
+				t := &Table{}
+				err = d.DecodeElement(t, &token)
+				blocks = append(blocks, Block{Table: t})
+			case "sdt":
+				var properties sdtProperties
+				var content []Block
+				content, err = decodeBlocks(d, func(start xml.StartElement) error {
+					if start.Name.Local == "sdtPr" {
+						return d.DecodeElement(&properties, &start)
+					}
+					return d.Skip()
+				})
+				if properties.Gallery.Val != "Table of Contents" {
+					blocks = append(blocks, content...)
+				}
+			case "sdtContent", "customXml":
+				var content []Block
+				content, err = decodeBlocks(d, nil)
+				blocks = append(blocks, content...)
+			default:
+				if other != nil {
+					err = other(token)
+				} else {
+					err = d.Skip()
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			return blocks, nil
+		}
+	}
+}
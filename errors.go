@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"loader/loaders"
+)
+
+// errInvalidArgument is returned when the arguments of the plugin method are not valid
+var errInvalidArgument = errors.New("invalid argument")
+
+// invalidArgument returns an invalid argument error with the message
+func invalidArgument(format string, a ...interface{}) error {
+	return loaders.NewError(errInvalidArgument, fmt.Errorf(format, a...))
+}
+
+// errorType is the response code and error type of an error kind
+type errorType struct {
+	kind error
+	code int
+	name string
+}
+
+// errorTypes are checked in order, the first kind matched by errors.Is is used
+var errorTypes = []errorType{
+	{errInvalidArgument, 400, "invalid_argument"},
+	{loaders.ErrEncrypted, 401, "encrypted"},
+	{loaders.ErrBadPassword, 403, "bad_password"},
+	{fs.ErrPermission, 403, "permission_denied"},
+	{fs.ErrNotExist, 404, "not_found"},
+	{context.DeadlineExceeded, 408, "timeout"},
+	{loaders.ErrTooLarge, 413, "too_large"},
+	{loaders.ErrUnsupportedType, 415, "unsupported_type"},
+	{loaders.ErrCorrupt, 422, "corrupt"},
+	{loaders.ErrEmptyDocument, 422, "empty_document"},
+	{context.Canceled, 499, "canceled"},
+}
+
+// errorResponse returns the response of the error with its code and type,
+// the message of the original error is kept in the cause.
+func errorResponse(err error) map[string]interface{} {
+	res := map[string]interface{}{"code": 500, "error_type": "internal_error", "message": err.Error()}
+	for _, t := range errorTypes {
+		if errors.Is(err, t.kind) {
+			res["code"] = t.code
+			res["error_type"] = t.name
+			break
+		}
+	}
+
+	var loaderErr *loaders.Error
+	if errors.As(err, &loaderErr) {
+		res["cause"] = loaderErr.Cause.Error()
+	}
+	return res
+}
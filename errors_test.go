@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"loader/loaders"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	_, err := os.Open("./yaoapp/data/missing.pdf")
+	res := errorResponse(err)
+	assert.Equal(t, 404, res["code"])
+	assert.Equal(t, "not_found", res["error_type"])
+
+	res = errorResponse(loaders.NewError(loaders.ErrBadPassword, fmt.Errorf("pdf: invalid password")))
+	assert.Equal(t, 403, res["code"])
+	assert.Equal(t, "bad_password", res["error_type"])
+	assert.Equal(t, "pdf: invalid password", res["cause"])
+
+	res = errorResponse(invalidArgument("unknown splitter: %s", "sentence"))
+	assert.Equal(t, 400, res["code"])
+	assert.Equal(t, "invalid_argument", res["error_type"])
+
+	res = errorResponse(fmt.Errorf("load: %w", context.DeadlineExceeded))
+	assert.Equal(t, 408, res["code"])
+	assert.Equal(t, "timeout", res["error_type"])
+
+	res = errorResponse(fmt.Errorf("unexpected"))
+	assert.Equal(t, 500, res["code"])
+	assert.NotContains(t, res, "cause")
+}
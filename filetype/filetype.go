@@ -0,0 +1,305 @@
+// Package filetype detects the document type of the files loaded by the plugin.
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"loader/cfb"
+)
+
+// The document types detected
+const (
+	DIR     = "DIR"
+	DOCX    = "DOCX"
+	XLSX    = "XLSX"
+	PPTX    = "PPTX"
+	DOC     = "DOC"
+	XLS     = "XLS"
+	PPT     = "PPT"
+	ODT     = "ODT"
+	ODS     = "ODS"
+	ODP     = "ODP"
+	PDF     = "PDF"
+	MD      = "MD"
+	HTML    = "HTML"
+	CSV     = "CSV"
+	WIZ     = "WIZ"
+	TEXT    = "TEXT"
+	Unknown = "Unknown"
+)
+
+// sniffLen is the number of bytes read from the head of the file to detect the type
+const sniffLen = 8192
+
+// Info is the detected type of a file
+type Info struct {
+	// Type is the document type, one of the constants above
+	Type string `json:"file_type"`
+	// MIME is the mime type of the content
+	MIME string `json:"mime_type"`
+	// Encoding is the text encoding found from the byte order mark, empty when there is none
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// mimeTypes are the mime types of the document types
+var mimeTypes = map[string]string{
+	DIR:     "inode/directory",
+	DOCX:    "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	XLSX:    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	PPTX:    "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	DOC:     "application/msword",
+	XLS:     "application/vnd.ms-excel",
+	PPT:     "application/vnd.ms-powerpoint",
+	ODT:     "application/vnd.oasis.opendocument.text",
+	ODS:     "application/vnd.oasis.opendocument.spreadsheet",
+	ODP:     "application/vnd.oasis.opendocument.presentation",
+	PDF:     "application/pdf",
+	MD:      "text/markdown",
+	HTML:    "text/html",
+	CSV:     "text/csv",
+	WIZ:     "application/x-wiz",
+	TEXT:    "text/plain",
+	Unknown: "application/octet-stream",
+}
+
+// extensions are the document types of the file extensions,
+// used when the type can not be told from the content
+var extensions = map[string]string{
+	".docx": DOCX,
+	".xlsx": XLSX,
+	".pptx": PPTX,
+	".doc":  DOC,
+	".xls":  XLS,
+	".ppt":  PPT,
+	".odt":  ODT,
+	".ods":  ODS,
+	".odp":  ODP,
+	".pdf":  PDF,
+	".md":   MD,
+	".mdx":  MD,
+	".html": HTML,
+	".htm":  HTML,
+	".csv":  CSV,
+	".ziw":  WIZ,
+	".txt":  TEXT,
+	".text": TEXT,
+	".log":  TEXT,
+}
+
+// newInfo returns the info of the document type
+func newInfo(ftype string) Info {
+	return Info{Type: ftype, MIME: mimeTypes[ftype]}
+}
+
+// Detect returns the file type based on the file content, the extension is used
+// to tell apart the text formats and as a fallback for the unrecognised content.
+func Detect(fileName string) (Info, error) {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return newInfo(Unknown), err
+	}
+	if info.IsDir() {
+		return newInfo(DIR), nil
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return newInfo(Unknown), err
+	}
+	defer f.Close()
+	return DetectReader(f, info.Size(), fileName)
+}
+
+// DetectReader returns the type of the content, name is only used for its extension.
+func DetectReader(r io.ReaderAt, size int64, name string) (Info, error) {
+	head := make([]byte, sniffLen)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return newInfo(Unknown), err
+	}
+	head = head[:n]
+	ext := strings.ToLower(filepath.Ext(name))
+
+	switch {
+	case isPDF(head):
+		return newInfo(PDF), nil
+
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		if ftype := detectZip(r, size); ftype != Unknown {
+			return newInfo(ftype), nil
+		}
+		return fromExtension(ext, "application/zip"), nil
+
+	case cfb.IsCompound(head):
+		if ftype := detectCompound(r); ftype != Unknown {
+			return newInfo(ftype), nil
+		}
+		// the encrypted docx, xlsx and pptx files are compound files too
+		return fromExtension(ext, "application/x-cfb"), nil
+
+	case bytes.HasPrefix(head, []byte("\xEF\xBB\xBF")):
+		info := detectText(head[3:], ext)
+		info.Encoding = "utf-8"
+		return info, nil
+
+	case bytes.HasPrefix(head, []byte("\xFF\xFE")):
+		info := detectText(nil, ext)
+		info.Encoding = "utf-16le"
+		return info, nil
+
+	case bytes.HasPrefix(head, []byte("\xFE\xFF")):
+		info := detectText(nil, ext)
+		info.Encoding = "utf-16be"
+		return info, nil
+	}
+
+	if isText(head, int64(n) < size) {
+		return detectText(head, ext), nil
+	}
+	return fromExtension(ext, mimeTypes[Unknown]), nil
+}
+
+// detectZip tells the office documents and wiz notes apart by the files in the zip container,
+// the OpenDocument files are told by their mimetype file
+func detectZip(r io.ReaderAt, size int64) string {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return Unknown
+	}
+
+	contentTypes := false
+	ftype := Unknown
+	for _, file := range zipReader.File {
+		switch {
+		case file.Name == "mimetype":
+			if ftype := openDocumentType(file); ftype != Unknown {
+				return ftype
+			}
+		case file.Name == "[Content_Types].xml":
+			contentTypes = true
+		case strings.HasPrefix(file.Name, "word/"):
+			ftype = DOCX
+		case strings.HasPrefix(file.Name, "xl/"):
+			ftype = XLSX
+		case strings.HasPrefix(file.Name, "ppt/"):
+			ftype = PPTX
+		case file.Name == "index.html" && ftype == Unknown:
+			ftype = WIZ
+		}
+	}
+	if ftype != WIZ && !contentTypes {
+		return Unknown
+	}
+	return ftype
+}
+
+// openDocumentType returns the type of the mime type in the mimetype file of an OpenDocument file
+func openDocumentType(file *zip.File) string {
+	rc, err := file.Open()
+	if err != nil {
+		return Unknown
+	}
+	defer rc.Close()
+	mime, err := io.ReadAll(io.LimitReader(rc, 128))
+	if err != nil {
+		return Unknown
+	}
+	for _, ftype := range []string{ODT, ODS, ODP} {
+		if string(bytes.TrimSpace(mime)) == mimeTypes[ftype] {
+			return ftype
+		}
+	}
+	return Unknown
+}
+
+// detectCompound tells the legacy office documents apart by the streams of the compound file
+func detectCompound(r io.ReaderAt) string {
+	names, err := cfb.Names(r)
+	if err != nil {
+		return Unknown
+	}
+	for _, name := range names {
+		switch name {
+		case "WordDocument":
+			return DOC
+		case "Workbook", "Book":
+			return XLS
+		case "PowerPoint Document":
+			return PPT
+		}
+	}
+	return Unknown
+}
+
+// detectText returns the text format, html is recognised from the content and the others from the extension
+func detectText(head []byte, ext string) Info {
+	if isHTML(head) {
+		return newInfo(HTML)
+	}
+	switch ftype := extensions[ext]; ftype {
+	case MD, CSV, HTML, TEXT:
+		return newInfo(ftype)
+	}
+	return newInfo(TEXT)
+}
+
+// isPDF checks the pdf header at the beginning of the content, after a byte order mark or white spaces
+// left by the tools producing the file. A header further in the content is text mentioning it.
+func isPDF(head []byte) bool {
+	head = bytes.TrimPrefix(head, []byte("\xEF\xBB\xBF"))
+	return bytes.HasPrefix(bytes.TrimLeft(head, " \t\r\n\f\x00"), []byte("%PDF-"))
+}
+
+// isHTML checks the doctype or the html tag at the beginning of the content
+func isHTML(head []byte) bool {
+	s := strings.ToLower(string(bytes.TrimSpace(head)))
+	return strings.HasPrefix(s, "<!doctype html") ||
+		strings.HasPrefix(s, "<html") ||
+		strings.HasPrefix(s, "<head") ||
+		(strings.HasPrefix(s, "<?xml") && strings.Contains(s, "<html"))
+}
+
+// isText checks if the content is utf-8 text without control characters,
+// truncated is true when head is only the beginning of the content.
+func isText(head []byte, truncated bool) bool {
+	if truncated {
+		// ignore the rune cut at the end of the head
+		for i := 1; i < utf8.UTFMax && i <= len(head); i++ {
+			if utf8.RuneStart(head[len(head)-i]) {
+				if !utf8.FullRune(head[len(head)-i:]) {
+					head = head[:len(head)-i]
+				}
+				break
+			}
+		}
+	}
+	if !utf8.Valid(head) {
+		return false
+	}
+
+	control := 0
+	for _, b := range head {
+		if b == 0 {
+			return false
+		}
+		if b < 32 && b != '\n' && b != '\r' && b != '\t' && b != '\f' && b != '\v' && b != 0x1b {
+			control++
+		}
+	}
+	return control*100 <= len(head)
+}
+
+// fromExtension returns the type of the extension, with the mime type of the content when it is unknown
+func fromExtension(ext string, mime string) Info {
+	ftype, ok := extensions[ext]
+	if !ok {
+		return Info{Type: Unknown, MIME: mime}
+	}
+	return newInfo(ftype)
+}
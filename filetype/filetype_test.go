@@ -0,0 +1,126 @@
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"loader/internal/cfbtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func zipBytes(t *testing.T, names ...string) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for _, name := range names {
+		_, err := w.Create(name)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func compoundBytes(names ...string) []byte {
+	streams := make([]cfbtest.Stream, len(names))
+	for i, name := range names {
+		streams[i] = cfbtest.Stream{Name: name, Data: []byte(name)}
+	}
+	return cfbtest.File(streams...)
+}
+
+func openDocumentBytes(t *testing.T, mime string) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	f, err := w.Create("mimetype")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(mime))
+	require.NoError(t, err)
+	_, err = w.Create("content.xml")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	tests := []struct {
+		name     string
+		content  []byte
+		ftype    string
+		encoding string
+	}{
+		{name: "upload", content: []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"), ftype: PDF},
+		{name: "padded", content: []byte("\xEF\xBB\xBF\r\n %PDF-1.7\n%\xe2\xe3\xcf\xd3\n"), ftype: PDF},
+		{name: "pdf.md", content: []byte("# PDF\nA pdf file starts with %PDF-1.7\n"), ftype: MD},
+		{name: "header.txt", content: []byte("Header: %PDF-"), ftype: TEXT},
+		{name: "report.doc", content: zipBytes(t, "[Content_Types].xml", "word/document.xml"), ftype: DOCX},
+		{name: "sheet", content: zipBytes(t, "[Content_Types].xml", "xl/workbook.xml"), ftype: XLSX},
+		{name: "slides.zip", content: zipBytes(t, "[Content_Types].xml", "ppt/presentation.xml"), ftype: PPTX},
+		{name: "note", content: zipBytes(t, "index.html", "index_files/a.png"), ftype: WIZ},
+		{name: "archive.zip", content: zipBytes(t, "a.txt"), ftype: Unknown},
+		{name: "chinese", content: []byte("中文文本文件，UTF-8 编码。\n第二行"), ftype: TEXT},
+		{name: "readme.md", content: []byte("# 标题\n内容"), ftype: MD},
+		{name: "page.txt", content: []byte("\n<!DOCTYPE html><html><body>hi</body></html>"), ftype: HTML},
+		{name: "bom.csv", content: []byte("\xEF\xBB\xBFname,age\n"), ftype: CSV, encoding: "utf-8"},
+		{name: "utf16.txt", content: []byte("\xFF\xFEh\x00i\x00"), ftype: TEXT, encoding: "utf-16le"},
+		{name: "image", content: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), ftype: Unknown},
+		{name: "broken.docx", content: []byte("\x00\x01\x02\x03"), ftype: DOCX},
+		{name: "text.zip", content: openDocumentBytes(t, "application/vnd.oasis.opendocument.text"), ftype: ODT},
+		{name: "cells", content: openDocumentBytes(t, "application/vnd.oasis.opendocument.spreadsheet"), ftype: ODS},
+		{name: "show", content: openDocumentBytes(t, "application/vnd.oasis.opendocument.presentation"), ftype: ODP},
+		{name: "drawing.odg", content: openDocumentBytes(t, "application/vnd.oasis.opendocument.graphics"), ftype: Unknown},
+		{name: "word", content: compoundBytes("1Table", "WordDocument"), ftype: DOC},
+		{name: "book.doc", content: compoundBytes("Workbook"), ftype: XLS},
+		{name: "deck", content: compoundBytes("Current User", "PowerPoint Document"), ftype: PPT},
+		{name: "secret.xlsx", content: compoundBytes("EncryptedInfo", "EncryptedPackage"), ftype: XLSX},
+		{name: "storage", content: compoundBytes("Contents"), ftype: Unknown},
+		{name: "truncated.ppt", content: []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1\x00"), ftype: PPT},
+	}
+	for _, tt := range tests {
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, tt.name), tt.content, 0o600))
+	}
+
+	for _, tt := range tests {
+		info, err := Detect(filepath.Join(tempDir, tt.name))
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.ftype, info.Type, tt.name)
+		assert.Equal(t, tt.encoding, info.Encoding, tt.name)
+		assert.NotEmpty(t, info.MIME, tt.name)
+	}
+
+	info, err := Detect(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, DIR, info.Type)
+
+	_, err = Detect(filepath.Join(tempDir, "missing"))
+	require.Error(t, err)
+}
+
+func TestDetectSamples(t *testing.T) {
+	t.Parallel()
+
+	samples := map[string]string{
+		"test.docx":  DOCX,
+		"test.xlsx":  XLSX,
+		"test.pptx":  PPTX,
+		"sample.pdf": PDF,
+		"test.html":  HTML,
+		"test.csv":   CSV,
+		"test.md":    MD,
+		"test.txt":   TEXT,
+		"test.ziw":   WIZ,
+	}
+	for name, ftype := range samples {
+		info, err := Detect(filepath.Join("..", "yaoapp", "data", name))
+		require.NoError(t, err, name)
+		assert.Equal(t, ftype, info.Type, name)
+	}
+}
@@ -11,6 +11,7 @@ require (
 	github.com/ledongthuc/pdf v0.0.0-20220302134840-0c2507a12d80
 	github.com/microcosm-cc/bluemonday v1.0.26
 	github.com/pkoukk/tiktoken-go v0.1.6
+	github.com/richardlehane/mscfb v1.0.4
 	github.com/stretchr/testify v1.9.0
 	github.com/xuri/excelize/v2 v2.8.0
 	github.com/yaoapp/kun v0.9.0
@@ -38,7 +39,6 @@ require (
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
 	github.com/oklog/run v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/richardlehane/mscfb v1.0.4 // indirect
 	github.com/richardlehane/msoleps v1.0.3 // indirect
 	github.com/xuri/efp v0.0.0-20230802181842-ad255f2331ca // indirect
 	github.com/xuri/nfp v0.0.0-20230819163627-dc951e3ffe1a // indirect
@@ -0,0 +1,145 @@
+// Package cfbtest builds small compound files for the tests of the legacy office formats.
+package cfbtest
+
+import (
+	"encoding/binary"
+	"unicode/utf16"
+)
+
+const (
+	sectorSize   = 512
+	miniCutoff   = 4096
+	freeSect     = 0xFFFFFFFF
+	endOfChain   = 0xFFFFFFFE
+	fatSect      = 0xFFFFFFFD
+	noStream     = 0xFFFFFFFF
+	dirEntrySize = 128
+)
+
+// Stream is a stream in the root storage of the file
+type Stream struct {
+	Name string
+	Data []byte
+}
+
+// File returns a version 3 compound file with the streams in the root storage. The streams are
+// padded with zeros to the mini stream cutoff, so they are all stored in regular sectors.
+func File(streams ...Stream) []byte {
+	data := make([][]byte, len(streams))
+	dataSectors := 0
+	for i, s := range streams {
+		data[i] = s.Data
+		if len(data[i]) < miniCutoff {
+			data[i] = append(append([]byte{}, s.Data...), make([]byte, miniCutoff-len(s.Data))...)
+		}
+		dataSectors += sectors(len(data[i]))
+	}
+	dirSectors := sectors((len(streams) + 1) * dirEntrySize)
+	fatSectors := 1
+	for fatSectors*sectorSize/4 < dataSectors+dirSectors+fatSectors {
+		fatSectors++
+	}
+
+	// the streams come first, then the directory and the fat
+	fat := make([]uint32, fatSectors*sectorSize/4)
+	for i := range fat {
+		fat[i] = freeSect
+	}
+	chain := func(start, count int) {
+		for i := start; i < start+count-1; i++ {
+			fat[i] = uint32(i + 1)
+		}
+		fat[start+count-1] = endOfChain
+	}
+	starts := make([]int, len(streams))
+	next := 0
+	for i := range data {
+		starts[i] = next
+		chain(next, sectors(len(data[i])))
+		next += sectors(len(data[i]))
+	}
+	dirStart := next
+	chain(dirStart, dirSectors)
+	fatStart := dirStart + dirSectors
+	for i := 0; i < fatSectors; i++ {
+		fat[fatStart+i] = fatSect
+	}
+
+	out := make([]byte, sectorSize*(1+fatStart+fatSectors))
+	header := out[:sectorSize]
+	copy(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	binary.LittleEndian.PutUint16(header[24:], 0x003E)
+	binary.LittleEndian.PutUint16(header[26:], 0x0003)
+	binary.LittleEndian.PutUint16(header[28:], 0xFFFE)
+	binary.LittleEndian.PutUint16(header[30:], 9)
+	binary.LittleEndian.PutUint16(header[32:], 6)
+	binary.LittleEndian.PutUint32(header[44:], uint32(fatSectors))
+	binary.LittleEndian.PutUint32(header[48:], uint32(dirStart))
+	binary.LittleEndian.PutUint32(header[56:], miniCutoff)
+	binary.LittleEndian.PutUint32(header[60:], endOfChain)
+	binary.LittleEndian.PutUint32(header[68:], endOfChain)
+	for i := 0; i < 109; i++ {
+		sect := uint32(freeSect)
+		if i < fatSectors {
+			sect = uint32(fatStart + i)
+		}
+		binary.LittleEndian.PutUint32(header[76+i*4:], sect)
+	}
+
+	sector := func(n int) []byte {
+		return out[sectorSize*(n+1):]
+	}
+	for i := range data {
+		copy(sector(starts[i]), data[i])
+	}
+
+	// the root has the first stream as its child, the others are linked as right siblings
+	dir := sector(dirStart)[:dirSectors*sectorSize]
+	for i := 0; i < len(dir)/dirEntrySize; i++ {
+		entry := dir[i*dirEntrySize:]
+		binary.LittleEndian.PutUint32(entry[68:], noStream)
+		binary.LittleEndian.PutUint32(entry[72:], noStream)
+		binary.LittleEndian.PutUint32(entry[76:], noStream)
+	}
+	root := dir[:dirEntrySize]
+	writeName(root, "Root Entry")
+	root[66] = 5
+	root[67] = 1
+	binary.LittleEndian.PutUint32(root[116:], endOfChain)
+	if len(streams) > 0 {
+		binary.LittleEndian.PutUint32(root[76:], 1)
+	}
+	for i, s := range streams {
+		entry := dir[(i+1)*dirEntrySize:]
+		writeName(entry, s.Name)
+		entry[66] = 2
+		entry[67] = 1
+		if i+1 < len(streams) {
+			binary.LittleEndian.PutUint32(entry[72:], uint32(i+2))
+		}
+		binary.LittleEndian.PutUint32(entry[116:], uint32(starts[i]))
+		binary.LittleEndian.PutUint64(entry[120:], uint64(len(data[i])))
+	}
+
+	for i, v := range fat {
+		binary.LittleEndian.PutUint32(sector(fatStart)[i*4:], v)
+	}
+	return out
+}
+
+// writeName writes the utf-16 name of the directory entry with its terminator
+func writeName(entry []byte, name string) {
+	units := utf16.Encode([]rune(name))
+	if len(units) > 31 {
+		units = units[:31]
+	}
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(entry[i*2:], u)
+	}
+	binary.LittleEndian.PutUint16(entry[64:], uint16((len(units)+1)*2))
+}
+
+// sectors returns the number of sectors of n bytes
+func sectors(n int) int {
+	return (n + sectorSize - 1) / sectorSize
+}
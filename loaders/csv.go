@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"strings"
 
 	"loader/schema"
@@ -20,7 +21,7 @@ type CSV struct {
 	columns []string
 }
 
-var _ Loader = CSV{}
+var _ IterLoader = CSV{}
 
 // NewCSV creates a new csv loader with an io.Reader and optional column names for filtering.
 func NewCSV(r io.Reader, columns ...string) CSV {
@@ -30,46 +31,65 @@ func NewCSV(r io.Reader, columns ...string) CSV {
 	}
 }
 
-// Load reads from the io.Reader and returns a single document with the data.
-func (c CSV) Load(_ context.Context) ([]schema.Document, error) {
-	var header []string
-	var docs []schema.Document
-	var rown int
+// Load reads from the io.Reader and returns a document for each row.
+func (c CSV) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, c.LoadIter(ctx))
+}
 
-	rd := csv.NewReader(c.r)
-	for {
-		row, err := rd.Read()
-		if errors.Is(err, io.EOF) {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-		if len(header) == 0 {
-			header = append(header, row...)
-			continue
-		}
+// LoadIter reads the rows one by one and yields a document for each row,
+// the cells are formatted as "column: value" lines.
+func (c CSV) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		var header []string
+		var rown int
 
-		var content []string
-		for i, value := range row {
-			if c.columns != nil &&
-				len(c.columns) > 0 &&
-				!slices.Contains(c.columns, header[i]) {
+		rd := csv.NewReader(c.r)
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+			row, err := rd.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					err = NewError(ErrCorrupt, err)
+				}
+				yield(schema.Document{}, err)
+				return
+			}
+			if len(header) == 0 {
+				header = append(header, row...)
+				// remove the utf-8 byte order mark written by excel and notion
+				header[0] = strings.TrimPrefix(header[0], "\ufeff")
 				continue
 			}
 
-			line := fmt.Sprintf("%s: %s", header[i], value)
-			content = append(content, line)
-		}
+			var content []string
+			for i, value := range row {
+				if c.columns != nil &&
+					len(c.columns) > 0 &&
+					!slices.Contains(c.columns, header[i]) {
+					continue
+				}
 
-		rown++
-		docs = append(docs, schema.Document{
-			PageContent: strings.Join(content, "\n"),
-			Metadata:    map[string]any{"row": rown},
-		})
-	}
+				line := fmt.Sprintf("%s: %s", header[i], value)
+				content = append(content, line)
+			}
 
-	return docs, nil
+			rown++
+			doc := schema.Document{
+				PageContent: strings.Join(content, "\n"),
+				Metadata:    map[string]any{"row": rown},
+			}
+			if !yield(doc, nil) {
+				return
+			}
+		}
+	}
 }
 
 // LoadAndSplit reads text data from the io.Reader and splits it into multiple
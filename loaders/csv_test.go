@@ -3,6 +3,7 @@ package loaders
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,3 +48,32 @@ func TestCSVLoaderWithFilteringColumns(t *testing.T) {
 	expected2 := "city: London"
 	assert.Equal(t, docs[1].PageContent, expected2)
 }
+
+func TestCSVLoaderCanceled(t *testing.T) {
+	t.Parallel()
+	loader := NewCSV(strings.NewReader("name,age\nJohn Doe,25\nJane Smith,32\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	docs, err := loader.Load(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, docs)
+}
+
+func TestCSVLoaderIter(t *testing.T) {
+	t.Parallel()
+	loader := NewCSV(strings.NewReader("name,age\nJohn Doe,25\nJane Smith,32\n"))
+
+	var docs []string
+	for doc, err := range loader.LoadIter(context.Background()) {
+		require.NoError(t, err)
+		docs = append(docs, doc.PageContent)
+		assert.Equal(t, len(docs), doc.Metadata["row"])
+		break
+	}
+	assert.Equal(t, []string{"name: John Doe\nage: 25"}, docs)
+
+	for _, err := range NewCSV(strings.NewReader("name,age\n\"John,25\n")).LoadIter(context.Background()) {
+		require.ErrorIs(t, err, ErrCorrupt)
+	}
+}
@@ -0,0 +1,255 @@
+package loaders
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"loader/filetype"
+	"loader/schema"
+	"loader/textsplitter"
+)
+
+// LoaderFunc creates the loader of a file found in the directory.
+// It returns a nil loader when the file type is not supported.
+type LoaderFunc func(info filetype.Info, f *os.File, size int64) (Loader, error)
+
+// DirectoryLoader loads the documents of all the supported files in a directory tree.
+type DirectoryLoader struct {
+	root        string
+	newLoader   LoaderFunc
+	include     []string
+	exclude     []string
+	maxDepth    int
+	maxFileSize int64
+	onError     func(path string, err error)
+}
+
+var _ Loader = DirectoryLoader{}
+
+// DirectoryOptions are options for the directory loader.
+type DirectoryOptions func(dir *DirectoryLoader)
+
+// DirWithInclude only loads the files matching one of the glob patterns.
+// Patterns without a slash are matched against the file name, the others against
+// the slash separated path relative to the root, "**" matches any number of directories.
+func DirWithInclude(patterns ...string) DirectoryOptions {
+	return func(dir *DirectoryLoader) {
+		dir.include = append(dir.include, patterns...)
+	}
+}
+
+// DirWithExclude skips the files and directories matching one of the glob patterns.
+func DirWithExclude(patterns ...string) DirectoryOptions {
+	return func(dir *DirectoryLoader) {
+		dir.exclude = append(dir.exclude, patterns...)
+	}
+}
+
+// DirWithMaxDepth sets the max depth of the walk, 1 only loads the files in the root directory.
+// Zero means no limit.
+func DirWithMaxDepth(depth int) DirectoryOptions {
+	return func(dir *DirectoryLoader) {
+		dir.maxDepth = depth
+	}
+}
+
+// DirWithMaxFileSize skips the files larger than size bytes. Zero means no limit.
+func DirWithMaxFileSize(size int64) DirectoryOptions {
+	return func(dir *DirectoryLoader) {
+		dir.maxFileSize = size
+	}
+}
+
+// DirWithErrorHandler sets the handler of the files failed to load,
+// the failed files are then skipped instead of aborting the whole load.
+func DirWithErrorHandler(handler func(path string, err error)) DirectoryOptions {
+	return func(dir *DirectoryLoader) {
+		dir.onError = handler
+	}
+}
+
+// NewDirectory creates a new directory loader, the files are loaded with the loaders created by newLoader.
+func NewDirectory(root string, newLoader LoaderFunc, opts ...DirectoryOptions) DirectoryLoader {
+	dir := DirectoryLoader{
+		root:      root,
+		newLoader: newLoader,
+	}
+	for _, opt := range opts {
+		opt(&dir)
+	}
+	return dir
+}
+
+// Load walks the directory tree and returns the documents of the files, with metadata attached of the
+// source path, the path relative to the root, the file type and the mime type. Files of unknown types are skipped.
+func (d DirectoryLoader) Load(ctx context.Context) ([]schema.Document, error) {
+	docs := []schema.Document{}
+	err := filepath.WalkDir(d.root, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if filePath == d.root {
+				return err
+			}
+			return d.fail(filePath, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		rel, err := filepath.Rel(d.root, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+
+		if matchAny(d.exclude, rel) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		depth := strings.Count(rel, "/") + 1
+		if entry.IsDir() {
+			if d.maxDepth > 0 && depth >= d.maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(d.include) > 0 && !matchAny(d.include, rel) {
+			return nil
+		}
+
+		fileDocs, err := d.loadFile(ctx, filePath, rel)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return d.fail(filePath, err)
+		}
+		docs = append(docs, fileDocs...)
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return docs, err
+		}
+		return nil, err
+	}
+	return docs, nil
+}
+
+// loadFile loads the documents of a single file
+func (d DirectoryLoader) loadFile(ctx context.Context, filePath string, rel string) ([]schema.Document, error) {
+	info, err := filetype.Detect(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if info.Type == filetype.Unknown || info.Type == filetype.DIR {
+		return nil, nil
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	finfo, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if d.maxFileSize > 0 && finfo.Size() > d.maxFileSize {
+		return nil, NewError(ErrTooLarge, fmt.Errorf("file size %d exceeds the limit %d", finfo.Size(), d.maxFileSize))
+	}
+
+	loader, err := d.newLoader(info, f, finfo.Size())
+	if err != nil {
+		return nil, err
+	}
+	if loader == nil {
+		return nil, nil
+	}
+	docs, err := loader.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !HasContent(docs) {
+		return nil, ErrEmptyDocument
+	}
+	for i := range docs {
+		if docs[i].Metadata == nil {
+			docs[i].Metadata = map[string]any{}
+		}
+		docs[i].Metadata["source"] = filePath
+		docs[i].Metadata["path"] = rel
+		docs[i].Metadata["file_type"] = info.Type
+		docs[i].Metadata["mime_type"] = info.MIME
+	}
+	return docs, nil
+}
+
+// fail passes the error to the error handler, or returns it to abort the walk when there is no handler
+func (d DirectoryLoader) fail(filePath string, err error) error {
+	if d.onError == nil {
+		return fmt.Errorf("%s: %w", filePath, err)
+	}
+	d.onError(filePath, err)
+	return nil
+}
+
+// LoadAndSplit loads the documents of the directory and splits them into multiple
+// documents using a text splitter.
+func (d DirectoryLoader) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := d.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
+
+// matchAny reports whether the slash separated relative path matches one of the patterns
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(filepath.ToSlash(pattern), rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches the path with the pattern, patterns without a slash are matched against
+// the base name, "**" matches zero or more directories.
+func matchGlob(pattern string, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
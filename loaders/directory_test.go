@@ -0,0 +1,130 @@
+package loaders
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"loader/filetype"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLoader(info filetype.Info, f *os.File, size int64) (Loader, error) {
+	switch info.Type {
+	case filetype.MD, filetype.TEXT:
+		return NewText(f), nil
+	case filetype.CSV:
+		return NewCSV(f), nil
+	}
+	return nil, nil
+}
+
+func TestDirectoryLoader(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"readme.md":             "# Readme",
+		"notes.txt":             "Foo Bar Baz",
+		"data/users.csv":        "name,age\nJohn,25\n",
+		"data/archive/old.md":   "# Old",
+		"node_modules/pkg.md":   "# Package",
+		"broken/bad.csv":        "name,age\n\"John,25\n",
+		"images/logo.png.bytes": "\x89PNG\x00\x01\x02",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(tempDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o700))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+	}
+
+	t.Run("Load", func(t *testing.T) {
+		t.Parallel()
+		failures := map[string]error{}
+		loader := NewDirectory(tempDir, newTestLoader,
+			DirWithExclude("node_modules"),
+			DirWithErrorHandler(func(path string, err error) {
+				failures[path] = err
+			}),
+		)
+		docs, err := loader.Load(context.Background())
+		require.NoError(t, err)
+
+		paths := []string{}
+		for _, doc := range docs {
+			paths = append(paths, doc.Metadata["path"].(string))
+		}
+		assert.Equal(t, []string{"data/archive/old.md", "data/users.csv", "notes.txt", "readme.md"}, paths)
+		assert.Equal(t, "CSV", docs[1].Metadata["file_type"])
+		assert.Equal(t, filepath.Join(tempDir, "data", "users.csv"), docs[1].Metadata["source"])
+		assert.Equal(t, 1, docs[1].Metadata["row"])
+
+		require.Len(t, failures, 1)
+		assert.Contains(t, failures, filepath.Join(tempDir, "broken", "bad.csv"))
+	})
+
+	t.Run("LoadAbort", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewDirectory(tempDir, newTestLoader).Load(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("LoadFiltered", func(t *testing.T) {
+		t.Parallel()
+		loader := NewDirectory(tempDir, newTestLoader,
+			DirWithInclude("*.md", "data/**/*.csv"),
+			DirWithExclude("**/archive", "node_modules"),
+			DirWithMaxDepth(2),
+			DirWithMaxFileSize(10),
+			DirWithErrorHandler(func(string, error) {}),
+		)
+		docs, err := loader.Load(context.Background())
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		assert.Equal(t, "readme.md", docs[0].Metadata["path"])
+	})
+}
+
+func TestDirectoryImagePlaceholders(t *testing.T) {
+	t.Parallel()
+
+	// a scanned document without any text is kept when its image only pages are returned as placeholders
+	data := buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Im1 5 0 R >> >> /Contents 4 0 R >>",
+		pdfStream("q 612 0 0 792 0 0 cm /Im1 Do Q"),
+		"<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 /Length 1 >>\nstream\n\x00\nendstream",
+	)
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "scan.pdf"), data, 0o600))
+
+	newLoader := func(placeholders bool) func(filetype.Info, *os.File, int64) (Loader, error) {
+		return func(info filetype.Info, f *os.File, size int64) (Loader, error) {
+			return NewPDF(f, size, PdfWithImagePlaceholders(placeholders)), nil
+		}
+	}
+
+	docs, err := NewDirectory(tempDir, newLoader(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, true, docs[0].Metadata["image_only"])
+	assert.Equal(t, "scan.pdf", docs[0].Metadata["path"])
+
+	_, err = NewDirectory(tempDir, newLoader(false)).Load(context.Background())
+	require.ErrorIs(t, err, ErrEmptyDocument)
+}
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, matchGlob("*.pdf", "docs/2023/report.pdf"))
+	assert.True(t, matchGlob("docs/**/*.pdf", "docs/report.pdf"))
+	assert.True(t, matchGlob("docs/**/*.pdf", "docs/2023/q1/report.pdf"))
+	assert.True(t, matchGlob("**/tmp", "a/b/tmp"))
+	assert.False(t, matchGlob("docs/*.pdf", "docs/2023/report.pdf"))
+	assert.False(t, matchGlob("*.pdf", "report.docx"))
+}
@@ -0,0 +1,83 @@
+package loaders
+
+import (
+	"context"
+	"io"
+	"iter"
+	"strings"
+
+	"loader/doc"
+	"loader/schema"
+	"loader/textsplitter"
+)
+
+// Doc loads the text of the Word 97-2003 binary documents.
+type Doc struct {
+	r io.ReaderAt
+}
+
+var _ IterLoader = Doc{}
+
+// NewDoc creates a new doc loader with an io.ReaderAt.
+func NewDoc(r io.ReaderAt) Doc {
+	return Doc{r: r}
+}
+
+// Load reads from the io.ReaderAt and returns a document for each group of paragraphs.
+func (d Doc) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, d.LoadIter(ctx))
+}
+
+// LoadIter reads the main text of the document and yields the groups of paragraphs separated by
+// the empty paragraphs, like the docx loader does.
+func (d Doc) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(schema.Document{}, err)
+			return
+		}
+		paragraphs, err := doc.Read(d.r)
+		if err != nil {
+			yield(schema.Document{}, compoundError(err))
+			return
+		}
+
+		groups := []string{}
+		group := ""
+		for _, p := range paragraphs {
+			if strings.TrimSpace(p) == "" {
+				if group != "" {
+					groups = append(groups, group)
+					group = ""
+				}
+				continue
+			}
+			group += p + "\n"
+		}
+		if group != "" {
+			groups = append(groups, group)
+		}
+
+		docs := make([]schema.Document, len(groups))
+		for i, text := range groups {
+			docs[i] = schema.Document{
+				PageContent: text,
+				Metadata: map[string]any{
+					"paragraph":       i,
+					"total_paragraph": len(groups),
+				},
+			}
+		}
+		yieldAll(ctx, docs, yield)
+	}
+}
+
+// LoadAndSplit reads text data from the io.ReaderAt and splits it into multiple
+// documents using a text splitter.
+func (d Doc) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := d.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
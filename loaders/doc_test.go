@@ -0,0 +1,83 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"loader/internal/cfbtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// docBytes returns a word document with the main text in two pieces, the first one
+// in 8-bit characters and the second one in utf-16
+func docBytes(flags uint16, compressed []byte, wide string) []byte {
+	chars := utf16.Encode([]rune(wide))
+	ccp := uint32(len(compressed) + len(chars))
+
+	word := make([]byte, 3072)
+	binary.LittleEndian.PutUint16(word, 0xA5EC)
+	binary.LittleEndian.PutUint16(word[0x0A:], flags|0x0200)
+	binary.LittleEndian.PutUint16(word[32:], 14)
+	binary.LittleEndian.PutUint16(word[62:], 22)
+	binary.LittleEndian.PutUint32(word[64+3*4:], ccp)
+	binary.LittleEndian.PutUint16(word[152:], 93)
+
+	// the piece table at the beginning of the table stream
+	table := []byte{0x02}
+	table = binary.LittleEndian.AppendUint32(table, 4*3+8*2)
+	table = binary.LittleEndian.AppendUint32(table, 0)
+	table = binary.LittleEndian.AppendUint32(table, uint32(len(compressed)))
+	table = binary.LittleEndian.AppendUint32(table, ccp)
+	table = binary.LittleEndian.AppendUint16(table, 0)
+	table = binary.LittleEndian.AppendUint32(table, 1024*2|0x40000000)
+	table = binary.LittleEndian.AppendUint16(table, 0)
+	table = binary.LittleEndian.AppendUint16(table, 0)
+	table = binary.LittleEndian.AppendUint32(table, 2048)
+	table = binary.LittleEndian.AppendUint16(table, 0)
+	// fcClx is 0 and lcbClx is the size of the piece table
+	binary.LittleEndian.PutUint32(word[154+33*8+4:], uint32(len(table)))
+
+	copy(word[1024:], compressed)
+	for i, c := range chars {
+		binary.LittleEndian.PutUint16(word[2048+i*2:], c)
+	}
+	return cfbtest.File(
+		cfbtest.Stream{Name: "WordDocument", Data: word},
+		cfbtest.Stream{Name: "1Table", Data: table},
+	)
+}
+
+func TestDoc(t *testing.T) {
+	t.Parallel()
+
+	data := docBytes(0,
+		[]byte("Title\rHello \x13 PAGE \\* MERGEFORMAT \x141\x15 \x93quoted\x94\x1Ekey\r\r"),
+		"中文\x07\x07cell\x07\x07\rend\vline\r")
+	docs, err := NewDoc(bytes.NewReader(data)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Title\nHello 1 “quoted”-key\n", docs[0].PageContent)
+	// the empty cell in the middle of the row is kept, the cells are on a single line
+	assert.Equal(t, "中文\t\tcell\nend\nline\n", docs[1].PageContent)
+	assert.Equal(t, map[string]any{"paragraph": 1, "total_paragraph": 2}, docs[1].Metadata)
+
+	// a ccpText larger than the stream only reads the text of the pieces, the WordDocument
+	// stream is the first one of the file, it starts at the second sector
+	data = docBytes(0, []byte("short\r"), "")
+	binary.LittleEndian.PutUint32(data[512+64+3*4:], 0xFFFFFFFF)
+	docs, err = NewDoc(bytes.NewReader(data)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "short\n", docs[0].PageContent)
+
+	_, err = NewDoc(bytes.NewReader(docBytes(0x0100, []byte("secret\r"), ""))).Load(context.Background())
+	require.ErrorIs(t, err, ErrEncrypted)
+
+	_, err = NewDoc(bytes.NewReader(cfbtest.File(cfbtest.Stream{Name: "1Table", Data: []byte{0}}))).Load(context.Background())
+	require.ErrorIs(t, err, ErrCorrupt)
+}
@@ -3,6 +3,7 @@ package loaders
 import (
 	"context"
 	"io"
+	"iter"
 	"regexp"
 	"strings"
 
@@ -13,32 +14,373 @@ import (
 
 // HTML loads parses and sanitizes html content from an io.Reader.
 type Docx struct {
-	r io.ReaderAt
-	s int64
+	r         io.ReaderAt
+	s         int64
+	ocr       OCRProvider
+	markdown  bool
+	tables    string
+	headers   bool
+	notes     string
+	comments  bool
+	revisions string
+	pages     bool
+	embedded  bool
 }
 
-var _ Loader = Docx{}
+var _ IterLoader = Docx{}
+
+// DocxOptions are options for the docx loader.
+type DocxOptions func(docx *Docx)
+
+// DocxWithOCR recognizes the text of the pictures with the OCR provider, the text is added after the text
+// of the group of paragraphs of the picture and the document has the metadata "ocr" set to true.
+func DocxWithOCR(ocr OCRProvider) DocxOptions {
+	return func(docx *Docx) {
+		docx.ocr = ocr
+	}
+}
+
+// DocxWithMarkdown converts the document into markdown, the headings are kept as "#" headings and the items
+// of the lists as bullets, so that the heading hierarchy can be kept in the chunks by the markdown splitter.
+// A single document is returned for the body instead of a document for each group of paragraphs.
+func DocxWithMarkdown(markdown bool) DocxOptions {
+	return func(docx *Docx) {
+		docx.markdown = markdown
+	}
+}
+
+const (
+	// DocxTablesMarkdown writes the tables as markdown tables, the first row is the header
+	DocxTablesMarkdown = "markdown"
+	// DocxTablesRows writes a line for each row of the tables, the cells are written "header: value"
+	DocxTablesRows = "rows"
+)
+
+// DocxWithTables sets the format of the tables, DocxTablesMarkdown or DocxTablesRows. The tables are written
+// in markdown by default when the document is converted into markdown, a line for each row otherwise.
+// Each table is a group of paragraphs of its own.
+func DocxWithTables(format string) DocxOptions {
+	return func(docx *Docx) {
+		docx.tables = format
+	}
+}
+
+// DocxWithHeaders returns the headers and the footers as documents of their own after the documents of the body,
+// with the metadata "type" set to "header" or "footer". The headers and the footers of the same text are returned once.
+func DocxWithHeaders(headers bool) DocxOptions {
+	return func(docx *Docx) {
+		docx.headers = headers
+	}
+}
+
+const (
+	// DocxNotesInline writes the text of the footnotes and of the endnotes in square brackets at their reference
+	DocxNotesInline = "inline"
+	// DocxNotesAppend writes markdown footnote references, e.g. "[^1]" or "[^e1]" for the endnotes,
+	// and the notes after the body
+	DocxNotesAppend = "append"
+)
+
+// DocxWithNotes resolves the references to the footnotes and to the endnotes, DocxNotesInline or DocxNotesAppend.
+// The references are left out by default.
+func DocxWithNotes(mode string) DocxOptions {
+	return func(docx *Docx) {
+		docx.notes = mode
+	}
+}
+
+// DocxWithComments returns the comments of the reviewers as documents of their own after the documents of the body,
+// with the metadata "type" set to "comment" and the metadata "author" and "date". The commented text is quoted
+// before the comment.
+func DocxWithComments(comments bool) DocxOptions {
+	return func(docx *Docx) {
+		docx.comments = comments
+	}
+}
+
+const (
+	// DocxRevisionsAccept keeps the inserted texts and leaves out the deleted texts of the tracked changes
+	DocxRevisionsAccept = "accept"
+	// DocxRevisionsReject keeps the deleted texts and leaves out the inserted texts of the tracked changes
+	DocxRevisionsReject = "reject"
+	// DocxRevisionsShow keeps both, the inserted texts are written "{+text+}" and the deleted texts "{-text-}"
+	DocxRevisionsShow = "show"
+)
+
+// DocxWithRevisions sets how the tracked changes are read, DocxRevisionsAccept, DocxRevisionsReject
+// or DocxRevisionsShow. The changes are accepted by default.
+func DocxWithRevisions(mode string) DocxOptions {
+	return func(docx *Docx) {
+		docx.revisions = mode
+	}
+}
+
+// DocxWithPages returns a document for each page with the metadata "page" and "total_pages" instead of
+// a document for each group of paragraphs. The pages are approximate, they start at the explicit page breaks
+// and at the page breaks rendered by Word when the document was last saved.
+func DocxWithPages(pages bool) DocxOptions {
+	return func(docx *Docx) {
+		docx.pages = pages
+	}
+}
+
+// DocxWithEmbedded loads the xlsx workbooks embedded in the document with the xlsx loader, their sheets
+// are returned after the other documents with the metadata "embedded" set to the name of the workbook.
+func DocxWithEmbedded(embedded bool) DocxOptions {
+	return func(docx *Docx) {
+		docx.embedded = embedded
+	}
+}
 
 // NewHTML creates a new html loader with an io.Reader.
-func NewDocx(r io.ReaderAt, size int64) Docx {
-	return Docx{r, size}
+func NewDocx(r io.ReaderAt, size int64, opts ...DocxOptions) Docx {
+	d := Docx{r: r, s: size}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return d
 }
 
-// Load reads from the io.Reader and returns a single document with the data.
-func (d Docx) Load(_ context.Context) ([]schema.Document, error) {
+// Load reads from the io.Reader and returns a document for each group of paragraphs separated by empty lines.
+func (d Docx) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, d.LoadIter(ctx))
+}
 
-	paragraphs, err := docx.Read(d.r, d.s)
-	if err != nil {
-		return nil, err
+// LoadIter reads the paragraphs of the document and yields a document for each group of paragraphs.
+func (d Docx) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(schema.Document{}, err)
+			return
+		}
+		blocks, err := docx.ReadBlocks(d.r, d.s)
+		if err != nil {
+			yield(schema.Document{}, openError(d.r, err))
+			return
+		}
+		// the properties and the embedded objects are optional, a document whose parts
+		// cannot be read is loaded without them
+		properties, _ := docx.ReadProperties(d.r, d.s)
+		embedded, _ := docx.ReadEmbedded(d.r, d.s)
+		// the properties of the document are added to all its documents
+		yield = withMetadata(yield, docxInfo(properties, embedded))
+
+		applyRevisions(blocks, d.revisions)
+		var media map[string]docx.Media
+		if d.ocr != nil {
+			media, err = docx.ReadMedia(d.r, d.s)
+			if err != nil {
+				yield(schema.Document{}, openError(d.r, err))
+				return
+			}
+		}
+		var styles docx.Styles
+		if d.markdown {
+			styles, err = docx.ReadStyles(d.r, d.s)
+			if err != nil {
+				yield(schema.Document{}, openError(d.r, err))
+				return
+			}
+		}
+		var parts docx.Parts
+		if d.headers || d.notes != "" || d.comments {
+			parts, err = docx.ReadParts(d.r, d.s)
+			if err != nil {
+				yield(schema.Document{}, openError(d.r, err))
+				return
+			}
+		}
+		// the parts and the embedded workbooks are returned after the body
+		extra := []schema.Document{}
+		if d.headers {
+			extra = append(extra, partDocuments(parts, d.tableFormat())...)
+		}
+		if d.comments {
+			extra = append(extra, commentDocuments(parts, blocks)...)
+		}
+		yieldExtra := func() {
+			if yieldAll(ctx, extra, yield) && d.embedded {
+				d.yieldWorkbooks(ctx, embedded, yield)
+			}
+		}
+		notes := newNoteResolver(d.notes, parts)
+		if notes != nil {
+			notes.resolve(blocks)
+		}
+
+		if d.pages {
+			pages := docx.Pages(blocks)
+			for i, page := range pages {
+				if err := ctx.Err(); err != nil {
+					yield(schema.Document{}, err)
+					return
+				}
+				doc, err := d.blocksDocument(ctx, page, styles, media)
+				if err != nil {
+					yield(schema.Document{}, err)
+					return
+				}
+				if i == len(pages)-1 {
+					doc.PageContent = d.withNotes(doc.PageContent, notes.notesText())
+				}
+				doc.Metadata["page"] = i + 1
+				doc.Metadata["total_pages"] = len(pages)
+				if !yield(doc, nil) {
+					return
+				}
+			}
+			yieldExtra()
+			return
+		}
+		if d.markdown {
+			doc, err := d.markdownDocument(ctx, blocks, styles, media)
+			if err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+			doc.PageContent = d.withNotes(doc.PageContent, notes.notesText())
+			if !yield(doc, nil) {
+				return
+			}
+			yieldExtra()
+			return
+		}
+		groups := paragraphGroups(blocks, d.tableFormat(), d.ocr != nil)
+		if text := notes.notesText(); text != "" {
+			groups = append(groups, paragraphGroup{text: text + "\n"})
+		}
+
+		numPages := len(groups)
+		for i, group := range groups {
+			if err := ctx.Err(); err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+			text, ocr, err := d.groupText(ctx, group, media)
+			if err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+			doc := schema.Document{
+				PageContent: text,
+				Metadata: map[string]any{
+					"paragraph":       i,
+					"total_paragraph": numPages,
+				},
+			}
+			if ocr {
+				doc.Metadata["ocr"] = true
+			}
+			if !yield(doc, nil) {
+				return
+			}
+		}
+		yieldExtra()
+	}
+}
+
+// blocksDocument returns a document of the blocks, in markdown or as the groups of paragraphs separated
+// by blank lines
+func (d Docx) blocksDocument(ctx context.Context, blocks []docx.Block, styles docx.Styles, media map[string]docx.Media) (schema.Document, error) {
+	if d.markdown {
+		return d.markdownDocument(ctx, blocks, styles, media)
 	}
+	doc := schema.Document{Metadata: map[string]any{}}
+	texts := []string{}
+	for _, group := range paragraphGroups(blocks, d.tableFormat(), d.ocr != nil) {
+		text, ocr, err := d.groupText(ctx, group, media)
+		if err != nil {
+			return schema.Document{}, err
+		}
+		if ocr {
+			doc.Metadata["ocr"] = true
+		}
+		texts = append(texts, text)
+	}
+	doc.PageContent = strings.Join(texts, "\n")
+	return doc, nil
+}
+
+// groupText returns the text of the group of paragraphs followed by the text recognized in its pictures,
+// ocr is true when text was recognized
+func (d Docx) groupText(ctx context.Context, group paragraphGroup, media map[string]docx.Media) (text string, ocr bool, err error) {
+	if len(group.images) == 0 {
+		return group.text, false, nil
+	}
+	recognized, err := recognizeImages(ctx, d.ocr, mediaImages(media, group.images))
+	if err != nil || recognized == "" {
+		return group.text, false, err
+	}
+	return group.text + recognized + "\n", true, nil
+}
+
+// withNotes returns the content followed by the notes written after the body
+func (d Docx) withNotes(content string, notes string) string {
+	if notes == "" {
+		return content
+	}
+	if d.markdown {
+		return strings.TrimPrefix(content+"\n\n"+notes, "\n\n")
+	}
+	return strings.TrimPrefix(content+"\n"+notes+"\n", "\n")
+}
+
+// paragraphGroup is the text of a group of paragraphs and the relationship ids of their pictures
+type paragraphGroup struct {
+	text   string
+	images []string
+}
+
+// tableFormat returns the format of the tables
+func (d Docx) tableFormat() string {
+	if d.tables != "" {
+		return d.tables
+	}
+	if d.markdown {
+		return DocxTablesMarkdown
+	}
+	return DocxTablesRows
+}
+
+// paragraphGroups joins the texts of the paragraphs, the groups are separated by the empty paragraphs
+// and each table is a group written in the format. The pictures are added to the group being read
+// when they are found when withImages is true, the pictures after the last text are a group of their own.
+func paragraphGroups(blocks []docx.Block, format string, withImages bool) []paragraphGroup {
 	re := regexp.MustCompile(`^\s*\n`)
 	re2 := regexp.MustCompile(`^\s*$`)
 
-	docs := []schema.Document{}
-	strs := make([]string, 0)
+	strs := make([]paragraphGroup, 0)
 
 	line := ""
-	for _, r := range paragraphs {
+	images := []string{}
+	for _, block := range blocks {
+		if block.Table != nil {
+			if line != "" {
+				strs = append(strs, paragraphGroup{text: line, images: images})
+				line = ""
+				images = []string{}
+			}
+			if withImages {
+				for _, p := range block.Table.Paragraphs() {
+					images = append(images, p.Images()...)
+				}
+			}
+			text := tableText(*block.Table, format)
+			if text != "" {
+				text += "\n"
+			}
+			if text != "" || len(images) > 0 {
+				strs = append(strs, paragraphGroup{text: text, images: images})
+				images = []string{}
+			}
+			continue
+		}
+
+		r := *block.Paragraph
+		if withImages {
+			images = append(images, r.Images()...)
+		}
+
 		// replace the \u00a0 in the p.Texts
 		for i, t := range r.Texts {
 			r.Texts[i].Content = strings.ReplaceAll(t.Content, "\u00a0", "")
@@ -64,36 +406,107 @@ func (d Docx) Load(_ context.Context) ([]schema.Document, error) {
 			line = strings.TrimSuffix(line, "\n")
 			line += t.Content + "\n"
 		}
-		// for _, r := range r.Runs {
-		// 使用页面分隔符来分割段落
-		// if len(r.LastRenderedPageBreak) > 0 {
-		// 	strs = append(strs, line)
-		// 	line = ""
-		// }
 
 		// 如果需要使用空行来分割段落，可以使用以下代码
 		if line != "" && len(r.Texts) == 0 {
-			strs = append(strs, line)
+			strs = append(strs, paragraphGroup{text: line, images: images})
 			line = ""
+			images = []string{}
+		}
+
+	}
+	if line != "" || len(images) > 0 {
+		strs = append(strs, paragraphGroup{text: line, images: images})
+	}
+	return strs
+}
+
+// markdownDocument returns the paragraphs as a markdown document, the text recognized in the pictures
+// of a paragraph is added after it when the OCR is enabled
+func (d Docx) markdownDocument(ctx context.Context, blocks []docx.Block, styles docx.Styles, media map[string]docx.Media) (schema.Document, error) {
+	doc := schema.Document{Metadata: map[string]any{}}
+	markdown := []markdownBlock{}
+	for _, block := range blocks {
+		if err := ctx.Err(); err != nil {
+			return schema.Document{}, err
+		}
+		images := []string{}
+		if block.Table != nil {
+			if text := tableText(*block.Table, d.tableFormat()); text != "" {
+				markdown = append(markdown, markdownBlock{text: text})
+			}
+			for _, p := range block.Table.Paragraphs() {
+				images = append(images, p.Images()...)
+			}
+		} else {
+			if paragraph, ok := markdownParagraph(styles, *block.Paragraph); ok {
+				markdown = append(markdown, paragraph)
+			}
+			images = block.Paragraph.Images()
 		}
+		if d.ocr == nil || len(images) == 0 {
+			continue
+		}
+		text, err := recognizeImages(ctx, d.ocr, mediaImages(media, images))
+		if err != nil {
+			return schema.Document{}, err
+		}
+		if text != "" {
+			markdown = append(markdown, markdownBlock{text: text})
+			doc.Metadata["ocr"] = true
+		}
+	}
+	doc.PageContent = joinMarkdownBlocks(markdown)
+	return doc, nil
+}
+
+// markdownBlock is a paragraph converted into markdown
+type markdownBlock struct {
+	text string
+	item bool
+}
 
+// markdownParagraph converts the paragraph into a heading, a list item or a paragraph of text,
+// ok is false when the paragraph has no text
+func markdownParagraph(styles docx.Styles, p docx.Paragraph) (block markdownBlock, ok bool) {
+	text := strings.TrimSpace(strings.ReplaceAll(p.Text(), "\u00a0", " "))
+	if text == "" {
+		return markdownBlock{}, false
+	}
+	if level := styles.HeadingLevel(p); level > 0 {
+		return markdownBlock{text: strings.Repeat("#", min(level, 6)) + " " + strings.Join(strings.Fields(text), " ")}, true
 	}
-	if line != "" {
-		strs = append(strs, line)
-		line = ""
+	if level, ok := styles.ListLevel(p); ok {
+		return markdownBlock{text: strings.Repeat("  ", level) + "- " + strings.Join(strings.Fields(text), " "), item: true}, true
 	}
+	return markdownBlock{text: text}, true
+}
 
-	numPages := len(strs)
-	for i, v := range strs {
-		docs = append(docs, schema.Document{
-			PageContent: v,
-			Metadata: map[string]any{
-				"paragraph":       i,
-				"total_paragraph": numPages,
-			},
-		})
+// joinMarkdownBlocks joins the blocks with blank lines, the items of a list are kept on consecutive lines
+func joinMarkdownBlocks(blocks []markdownBlock) string {
+	var sb strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			sb.WriteString("\n")
+			if !block.item || !blocks[i-1].item {
+				sb.WriteString("\n")
+			}
+		}
+		sb.WriteString(block.text)
+	}
+	return sb.String()
+}
+
+// mediaImages returns the image files of the relationship ids, the files of unknown types are left out
+func mediaImages(media map[string]docx.Media, ids []string) []imageFile {
+	files := []imageFile{}
+	for _, id := range ids {
+		m, ok := media[id]
+		if mime := imageMIME(m.Name); ok && mime != "" {
+			files = append(files, imageFile{name: m.Name, mime: mime, data: m.Data})
+		}
 	}
-	return docs, nil
+	return files
 }
 
 // LoadAndSplit reads text data from the io.Reader and splits it into multiple
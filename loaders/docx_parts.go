@@ -0,0 +1,187 @@
+package loaders
+
+import (
+	"strings"
+
+	"loader/docx"
+	"loader/schema"
+)
+
+// The kinds of the documents of the parts of the docx files, the "type" metadata of the documents
+const (
+	docxHeader  = "header"
+	docxFooter  = "footer"
+	docxComment = "comment"
+)
+
+// noteResolver writes the notes referenced by the paragraphs in their text or after the body
+type noteResolver struct {
+	mode      string
+	footnotes map[string]docx.Note
+	endnotes  map[string]docx.Note
+	// appended are the notes written after the body in the DocxNotesAppend mode, in order of reference
+	appended []string
+	seen     map[string]bool
+}
+
+// newNoteResolver creates the resolver of the notes of the parts, it is nil when the mode is empty
+func newNoteResolver(mode string, parts docx.Parts) *noteResolver {
+	if mode == "" {
+		return nil
+	}
+	return &noteResolver{mode: mode, footnotes: parts.Footnotes, endnotes: parts.Endnotes, seen: map[string]bool{}}
+}
+
+// resolve replaces the references to the notes of the paragraphs of the blocks, the paragraphs of the tables
+// included, by the text of the notes in the DocxNotesInline mode and by markdown footnote references,
+// e.g. "[^1]", in the DocxNotesAppend mode.
+func (n *noteResolver) resolve(blocks []docx.Block) {
+	docx.EachParagraph(blocks, func(p *docx.Paragraph) {
+		resolved := false
+		for i, t := range p.Content {
+			if t.Reference == nil || (t.Reference.Kind != docx.ReferenceFootnote && t.Reference.Kind != docx.ReferenceEndnote) {
+				continue
+			}
+			if text := n.note(*t.Reference); text != "" {
+				p.Content[i] = docx.Text{Content: text}
+				resolved = true
+			}
+		}
+		if resolved {
+			resetTexts(p)
+		}
+	})
+}
+
+// note returns the text replacing the reference to the note, it is empty when the note is not found
+func (n *noteResolver) note(reference docx.Reference) string {
+	note, ok := n.footnotes[reference.ID]
+	label := reference.ID
+	if reference.Kind == docx.ReferenceEndnote {
+		note, ok = n.endnotes[reference.ID]
+		label = "e" + reference.ID
+	}
+	if !ok {
+		return ""
+	}
+	text := paragraphsText(note.Paragraphs, " ")
+	if n.mode == DocxNotesInline {
+		return "[" + text + "]"
+	}
+	if !n.seen[label] {
+		n.seen[label] = true
+		n.appended = append(n.appended, "[^"+label+"]: "+text)
+	}
+	return "[^" + label + "]"
+}
+
+// notesText returns the notes written after the body, one by line
+func (n *noteResolver) notesText() string {
+	if n == nil {
+		return ""
+	}
+	return strings.Join(n.appended, "\n")
+}
+
+// partDocuments returns a document for each header and footer with text, the parts of the same text
+// are returned once, e.g. the headers of the first page and of the other pages
+func partDocuments(parts docx.Parts, format string) []schema.Document {
+	docs := []schema.Document{}
+	seen := map[string]bool{}
+	for _, kind := range []string{docxHeader, docxFooter} {
+		list := parts.Headers
+		if kind == docxFooter {
+			list = parts.Footers
+		}
+		for _, part := range list {
+			text := blocksText(part.Blocks, format)
+			if text == "" || seen[kind+"\n"+text] {
+				continue
+			}
+			seen[kind+"\n"+text] = true
+			docs = append(docs, schema.Document{PageContent: text, Metadata: map[string]any{"type": kind}})
+		}
+	}
+	return docs
+}
+
+// commentDocuments returns a document for each comment, the commented text is quoted before the comment
+// and the comment is prefixed by its author. The author and the date are added to the metadata.
+func commentDocuments(parts docx.Parts, blocks []docx.Block) []schema.Document {
+	quotes := commentQuotes(blocks)
+	docs := []schema.Document{}
+	for _, comment := range parts.Comments {
+		text := paragraphsText(comment.Paragraphs, "\n")
+		if text == "" {
+			continue
+		}
+		author := strings.TrimSpace(comment.Author)
+		if author != "" {
+			text = author + ": " + text
+		}
+		if quote := strings.Join(strings.Fields(quotes[comment.ID]), " "); quote != "" {
+			text = "\"" + quote + "\" " + text
+		}
+		metadata := map[string]any{"type": docxComment}
+		if author != "" {
+			metadata["author"] = author
+		}
+		if comment.Date != "" {
+			metadata["date"] = comment.Date
+		}
+		docs = append(docs, schema.Document{PageContent: text, Metadata: metadata})
+	}
+	return docs
+}
+
+// commentQuotes returns the commented texts by comment id, the ranges of the comments may span paragraphs
+func commentQuotes(blocks []docx.Block) map[string]string {
+	quotes := map[string]string{}
+	open := map[string]bool{}
+	docx.EachParagraph(blocks, func(p *docx.Paragraph) {
+		for _, t := range p.Content {
+			switch {
+			case t.Reference != nil && t.Reference.Kind == docx.ReferenceCommentStart:
+				open[t.Reference.ID] = true
+			case t.Reference != nil && t.Reference.Kind == docx.ReferenceCommentEnd:
+				delete(open, t.Reference.ID)
+			case t.Revision != docx.RevisionDeleted:
+				for id := range open {
+					quotes[id] += t.Content
+				}
+			}
+		}
+		for id := range open {
+			quotes[id] += "\n"
+		}
+	})
+	return quotes
+}
+
+// paragraphsText returns the texts of the paragraphs joined by sep, the empty paragraphs are left out
+func paragraphsText(paragraphs []docx.Paragraph, sep string) string {
+	texts := []string{}
+	for _, p := range paragraphs {
+		if text := strings.TrimSpace(p.Text()); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, sep)
+}
+
+// blocksText returns the texts of the paragraphs and of the tables on lines, the tables in the format
+func blocksText(blocks []docx.Block, format string) string {
+	lines := []string{}
+	for _, block := range blocks {
+		text := ""
+		if block.Table != nil {
+			text = tableText(*block.Table, format)
+		} else {
+			text = strings.TrimSpace(block.Paragraph.Text())
+		}
+		if text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,106 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"loader/docx"
+	"loader/schema"
+)
+
+// docxInfo returns the properties of the document and the names of its embedded files as the metadata
+// of its documents, the properties that are not set are left out
+func docxInfo(properties docx.Properties, embedded []docx.Embedded) map[string]any {
+	info := map[string]any{}
+	for _, field := range []struct{ name, value string }{
+		{"title", properties.Title},
+		{"subject", properties.Subject},
+		{"author", properties.Creator},
+		{"keywords", properties.Keywords},
+		{"last_modified_by", properties.LastModifiedBy},
+		{"creation_date", properties.Created},
+		{"modified_date", properties.Modified},
+	} {
+		value := strings.TrimSpace(field.value)
+		if value == "" {
+			continue
+		}
+		if date, err := time.Parse(time.RFC3339, value); err == nil {
+			value = date.Format(time.RFC3339)
+		}
+		info[field.name] = value
+	}
+	if revision, err := strconv.Atoi(strings.TrimSpace(properties.Revision)); err == nil {
+		info["revision"] = revision
+	}
+	if properties.Words > 0 {
+		info["word_count"] = properties.Words
+	}
+
+	names := []string{}
+	for _, e := range embedded {
+		names = append(names, e.Name)
+	}
+	if len(names) > 0 {
+		info["embedded_objects"] = names
+	}
+	return info
+}
+
+// withMetadata returns a yield function adding the metadata to the documents,
+// the metadata set by the loader take precedence
+func withMetadata(yield func(schema.Document, error) bool, metadata map[string]any) func(schema.Document, error) bool {
+	if len(metadata) == 0 {
+		return yield
+	}
+	return func(doc schema.Document, err error) bool {
+		if err == nil {
+			if doc.Metadata == nil {
+				doc.Metadata = map[string]any{}
+			}
+			for key, value := range metadata {
+				if _, ok := doc.Metadata[key]; !ok {
+					doc.Metadata[key] = value
+				}
+			}
+		}
+		return yield(doc, err)
+	}
+}
+
+// isWorkbook reports whether the embedded file is a xlsx workbook
+func isWorkbook(e docx.Embedded) bool {
+	name := strings.ToLower(e.Name)
+	return e.Type == docx.EmbeddedPackage && (strings.HasSuffix(name, ".xlsx") || strings.HasSuffix(name, ".xlsm"))
+}
+
+// yieldWorkbooks loads the embedded xlsx workbooks with the xlsx loader and yields their sheets
+// with the metadata "embedded" set to the name of the workbook in the document, it returns false
+// when the iteration is stopped
+func (d Docx) yieldWorkbooks(ctx context.Context, embedded []docx.Embedded, yield func(schema.Document, error) bool) bool {
+	for _, e := range embedded {
+		if !isWorkbook(e) {
+			continue
+		}
+		data, err := docx.ReadPart(d.r, d.s, e.Name)
+		if err != nil {
+			yield(schema.Document{}, openError(d.r, err))
+			return false
+		}
+		for doc, err := range NewExcelx(bytes.NewReader(data)).LoadIter(ctx) {
+			if err != nil {
+				yield(schema.Document{}, fmt.Errorf("embedded %s: %w", e.Name, err))
+				return false
+			}
+			doc.Metadata["embedded"] = e.Name
+			if !yield(doc, nil) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package loaders
+
+import "loader/docx"
+
+// applyRevisions applies the mode to the tracked changes of the paragraphs of the blocks, DocxRevisionsAccept,
+// DocxRevisionsReject or DocxRevisionsShow, the changes are accepted when the mode is empty.
+// The texts of the changes are replaced by texts without revision.
+func applyRevisions(blocks []docx.Block, mode string) {
+	docx.EachParagraph(blocks, func(p *docx.Paragraph) {
+		changed := false
+		for _, t := range p.Content {
+			changed = changed || t.Revision != ""
+		}
+		if !changed {
+			return
+		}
+
+		content := make([]docx.Text, 0, len(p.Content))
+		for _, t := range p.Content {
+			switch {
+			case t.Revision == "":
+				content = append(content, t)
+			case mode == DocxRevisionsShow:
+				// the consecutive texts of the same revision are marked together
+				if n := len(content); n > 0 && content[n-1].Revision == t.Revision {
+					content[n-1].Content += t.Content
+					continue
+				}
+				content = append(content, t)
+			case (t.Revision == docx.RevisionInserted) == (mode != DocxRevisionsReject):
+				t.Revision = ""
+				content = append(content, t)
+			}
+		}
+		for i, t := range content {
+			switch t.Revision {
+			case docx.RevisionInserted:
+				content[i] = docx.Text{Content: "{+" + t.Content + "+}", PageBreak: t.PageBreak}
+			case docx.RevisionDeleted:
+				content[i] = docx.Text{Content: "{-" + t.Content + "-}", PageBreak: t.PageBreak}
+			}
+		}
+		p.Content = content
+		resetTexts(p)
+	})
+}
+
+// resetTexts replaces the texts of the runs and of the links of the paragraph by its content,
+// they are read apart when the paragraphs are grouped
+func resetTexts(p *docx.Paragraph) {
+	p.Texts = nil
+	p.Hyperlink = nil
+	if text := p.Text(); text != "" {
+		p.Texts = []docx.Text{{Content: text}}
+	}
+}
@@ -0,0 +1,117 @@
+package loaders
+
+import (
+	"strings"
+
+	"loader/docx"
+)
+
+// tableText returns the table in the format, DocxTablesMarkdown or DocxTablesRows.
+func tableText(table docx.Table, format string) string {
+	grid := table.Grid()
+	cells := make([][]string, 0, len(grid))
+	for _, row := range grid {
+		texts := make([]string, len(row))
+		for c, cell := range row {
+			texts[c] = cellText(cell)
+		}
+		cells = append(cells, texts)
+	}
+	return gridText(cells, func(r, c int) bool {
+		return c > 0 && c < len(grid[r]) && grid[r][c] == grid[r][c-1]
+	}, format)
+}
+
+// gridText returns the texts of the cells of a table in the format, spanned tells the cells continuing
+// the cell on their left. The tables of a single column are written as lines of text, they are used as frames.
+func gridText(grid [][]string, spanned func(r, c int) bool, format string) string {
+	columns := 0
+	for _, row := range grid {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+	cells := make([][]string, 0, len(grid))
+	for _, row := range grid {
+		texts := make([]string, columns)
+		copy(texts, row)
+		cells = append(cells, texts)
+	}
+
+	if columns == 1 {
+		lines := []string{}
+		for _, row := range cells {
+			if row[0] != "" {
+				lines = append(lines, row[0])
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	if format == DocxTablesRows {
+		return tableRows(cells, spanned)
+	}
+	return layoutTable{cells: cells}.markdown()
+}
+
+// tableRows returns a line for each row of the table after the header, the cells are written "header: value".
+// The cells spanning several columns are written once, the empty cells are left out.
+func tableRows(cells [][]string, spanned func(r, c int) bool) string {
+	header := cells[0]
+	if len(cells) == 1 {
+		return joinCells(header, nil, " | ", func(c int) bool { return spanned(0, c) })
+	}
+	lines := []string{}
+	for r := 1; r < len(cells); r++ {
+		if line := joinCells(cells[r], header, "; ", func(c int) bool { return spanned(r, c) }); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinCells joins the texts of the cells of a row, prefixed by the header when it is set
+func joinCells(texts []string, header []string, sep string, spanned func(c int) bool) string {
+	parts := []string{}
+	for c, text := range texts {
+		if text == "" || spanned(c) {
+			continue
+		}
+		if header != nil && header[c] != "" && header[c] != text {
+			text = header[c] + ": " + text
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, sep)
+}
+
+// cellText returns the text of the cell on a line, the rows of the nested tables are separated by semicolons
+func cellText(cell *docx.Cell) string {
+	if cell == nil {
+		return ""
+	}
+	parts := []string{}
+	for _, block := range cell.Blocks {
+		text := ""
+		if block.Paragraph != nil {
+			text = block.Paragraph.Text()
+		} else {
+			rows := []string{}
+			for _, row := range block.Table.Grid() {
+				texts := make([]string, len(row))
+				for c, nested := range row {
+					texts[c] = cellText(nested)
+				}
+				spanned := func(c int) bool { return c > 0 && row[c] == row[c-1] }
+				if row := joinCells(texts, nil, " | ", spanned); row != "" {
+					rows = append(rows, row)
+				}
+			}
+			text = strings.Join(rows, "; ")
+		}
+		if text = strings.Join(strings.Fields(text), " "); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
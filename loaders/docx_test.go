@@ -1,13 +1,19 @@
 package loaders
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"hash/crc32"
+	"loader/schema"
 	"loader/textsplitter"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 )
 
 func TestNewDocx(t *testing.T) {
@@ -32,3 +38,300 @@ func TestNewDocx(t *testing.T) {
 	expectedMetadata := map[string]any{}
 	assert.Equal(t, expectedMetadata, docs[0].Metadata)
 }
+
+// zipBytes returns a zip archive of the files given as pairs of name and content
+func zipBytes(t *testing.T, files ...string) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for i := 0; i+1 < len(files); i += 2 {
+		f, err := w.Create(files[i])
+		require.NoError(t, err)
+		_, err = f.Write([]byte(files[i+1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestDocxMarkdown(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"word/document.xml", `<w:document xmlns:w="w"><w:body>`+
+			`<w:p><w:pPr><w:pStyle w:val="1"/></w:pPr><w:r><w:t>Guide</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t xml:space="preserve">See the </w:t></w:r><w:hyperlink><w:r><w:t>manual</w:t></w:r></w:hyperlink><w:r><w:t> first.</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Install</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="3"/></w:numPr></w:pPr><w:r><w:t>Download</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="3"/></w:numPr></w:pPr><w:r><w:t>Unpack</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:pStyle w:val="ListBullet"/></w:pPr><w:r><w:t>Run</w:t></w:r></w:p>`+
+			`<w:p></w:p>`+
+			`<w:p><w:pPr><w:outlineLvl w:val="2"/></w:pPr><w:r><w:t>Notes</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:pStyle w:val="1"/><w:outlineLvl w:val="9"/></w:pPr><w:r><w:t>Done</w:t></w:r></w:p>`+
+			`</w:body></w:document>`,
+		"word/styles.xml", `<w:styles xmlns:w="w">`+
+			`<w:style w:type="paragraph" w:styleId="1"><w:name w:val="heading 1"/><w:pPr><w:outlineLvl w:val="0"/></w:pPr></w:style>`+
+			`<w:style w:type="paragraph" w:styleId="ListBullet"><w:name w:val="List Bullet"/><w:pPr><w:numPr><w:numId w:val="4"/></w:numPr></w:pPr></w:style>`+
+			`</w:styles>`,
+	)
+
+	docs, err := NewDocx(bytes.NewReader(data), int64(len(data)), DocxWithMarkdown(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "# Guide\n\nSee the manual first.\n\n## Install\n\n- Download\n  - Unpack\n- Run\n\n### Notes\n\nDone", docs[0].PageContent)
+
+	splitter := textsplitter.NewMarkdownTextSplitter(textsplitter.WithChunkSize(30), textsplitter.WithChunkOverlap(0), textsplitter.WithHeadingHierarchy(true))
+	chunks, err := textsplitter.SplitDocuments(splitter, docs)
+	require.NoError(t, err)
+	require.NotEmpty(t, chunks)
+	assert.Contains(t, chunks[len(chunks)-1].PageContent, "# Guide")
+
+	docs, err = NewDocx(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, docs[0].PageContent, "#")
+	assert.NotContains(t, docs[0].PageContent, "- ")
+}
+
+func TestDocxTables(t *testing.T) {
+	t.Parallel()
+
+	cell := func(props, text string) string {
+		return `<w:tc><w:tcPr>` + props + `</w:tcPr><w:p><w:r><w:t>` + text + `</w:t></w:r></w:p></w:tc>`
+	}
+	nested := `<w:tc><w:tbl>` +
+		`<w:tr>` + cell("", "a") + cell("", "b") + `</w:tr>` +
+		`<w:tr>` + cell("", "c") + cell("", "d") + `</w:tr>` +
+		`</w:tbl><w:p/></w:tc>`
+	data := zipBytes(t,
+		"word/document.xml", `<w:document xmlns:w="w"><w:body>`+
+			`<w:sdt><w:sdtPr><w:docPartObj><w:docPartGallery w:val="Table of Contents"/></w:docPartObj></w:sdtPr>`+
+			`<w:sdtContent><w:p><w:r><w:t>Contents 1</w:t></w:r></w:p></w:sdtContent></w:sdt>`+
+			`<w:p><w:r><w:t>Intro</w:t></w:r></w:p>`+
+			`<w:tbl>`+
+			`<w:tr>`+cell("", "Name")+cell(`<w:gridSpan w:val="2"/>`, "Score")+`</w:tr>`+
+			`<w:tr>`+cell(`<w:vMerge w:val="restart"/>`, "Ann")+cell("", "90")+cell("", "95")+`</w:tr>`+
+			`<w:tr>`+cell(`<w:vMerge/>`, "")+cell("", "80")+nested+`</w:tr>`+
+			`</w:tbl>`+
+			`<w:sdt><w:sdtContent><w:p><w:r><w:t>After</w:t></w:r></w:p></w:sdtContent></w:sdt>`+
+			`</w:body></w:document>`,
+	)
+
+	docs, err := NewDocx(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.Equal(t, "Intro\n", docs[0].PageContent)
+	assert.Equal(t, "Name: Ann; Score: 90; Score: 95\nName: Ann; Score: 80; Score: a | b; c | d\n", docs[1].PageContent)
+	assert.Equal(t, "After\n", docs[2].PageContent)
+
+	docs, err = NewDocx(bytes.NewReader(data), int64(len(data)), DocxWithMarkdown(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Intro\n\n"+
+		"| Name | Score | Score |\n"+
+		"| --- | --- | --- |\n"+
+		"| Ann | 90 | 95 |\n"+
+		"| Ann | 80 | a \\| b; c \\| d |\n\n"+
+		"After", docs[0].PageContent)
+
+	docs, err = NewDocx(bytes.NewReader(data), int64(len(data)), DocxWithTables(DocxTablesMarkdown)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.True(t, strings.HasPrefix(docs[1].PageContent, "| Name | Score | Score |\n"))
+}
+
+func TestDocxParts(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"word/document.xml", `<w:document xmlns:w="w"><w:body>`+
+			`<w:p><w:r><w:t xml:space="preserve">The term </w:t></w:r>`+
+			`<w:commentRangeStart w:id="0"/><w:r><w:t>is one year</w:t></w:r><w:commentRangeEnd w:id="0"/>`+
+			`<w:r><w:footnoteReference w:id="1"/></w:r><w:r><w:t>.</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t>Fees apply</w:t></w:r><w:r><w:endnoteReference w:id="1"/></w:r></w:p>`+
+			`</w:body></w:document>`,
+		"word/header1.xml", `<w:hdr xmlns:w="w"><w:p><w:r><w:t>Contract</w:t></w:r></w:p></w:hdr>`,
+		"word/header2.xml", `<w:hdr xmlns:w="w"><w:p><w:r><w:t>Contract</w:t></w:r></w:p></w:hdr>`,
+		"word/header3.xml", `<w:hdr xmlns:w="w"><w:p/></w:hdr>`,
+		"word/footer1.xml", `<w:ftr xmlns:w="w"><w:p><w:r><w:t>Confidential</w:t></w:r></w:p></w:ftr>`,
+		"word/footnotes.xml", `<w:footnotes xmlns:w="w">`+
+			`<w:footnote w:type="separator" w:id="-1"><w:p><w:r><w:separator/></w:r></w:p></w:footnote>`+
+			`<w:footnote w:id="1"><w:p><w:r><w:footnoteRef/></w:r><w:r><w:t xml:space="preserve"> Renewable.</w:t></w:r></w:p></w:footnote>`+
+			`</w:footnotes>`,
+		"word/endnotes.xml", `<w:endnotes xmlns:w="w"><w:endnote w:id="1"><w:p><w:r><w:t>See annex.</w:t></w:r></w:p></w:endnote></w:endnotes>`,
+		"word/comments.xml", `<w:comments xmlns:w="w">`+
+			`<w:comment w:id="0" w:author="Legal" w:date="2024-03-01T10:00:00Z"><w:p><w:r><w:t>Too short</w:t></w:r></w:p></w:comment>`+
+			`</w:comments>`,
+	)
+	load := func(opts ...DocxOptions) []schema.Document {
+		docs, err := NewDocx(bytes.NewReader(data), int64(len(data)), opts...).Load(context.Background())
+		require.NoError(t, err)
+		return docs
+	}
+
+	docs := load(DocxWithMarkdown(true), DocxWithNotes(DocxNotesInline))
+	require.Len(t, docs, 1)
+	assert.Equal(t, "The term is one year[Renewable.].\n\nFees apply[See annex.]", docs[0].PageContent)
+
+	docs = load(DocxWithMarkdown(true), DocxWithNotes(DocxNotesAppend), DocxWithHeaders(true), DocxWithComments(true))
+	require.Len(t, docs, 4)
+	assert.Equal(t, "The term is one year[^1].\n\nFees apply[^e1]\n\n[^1]: Renewable.\n[^e1]: See annex.", docs[0].PageContent)
+	assert.Equal(t, schema.Document{PageContent: "Contract", Metadata: map[string]any{"type": "header"}}, docs[1])
+	assert.Equal(t, schema.Document{PageContent: "Confidential", Metadata: map[string]any{"type": "footer"}}, docs[2])
+	assert.Equal(t, schema.Document{
+		PageContent: "\"is one year\" Legal: Too short",
+		Metadata:    map[string]any{"type": "comment", "author": "Legal", "date": "2024-03-01T10:00:00Z"},
+	}, docs[3])
+
+	docs = load(DocxWithNotes(DocxNotesAppend))
+	require.Len(t, docs, 2)
+	assert.Equal(t, "The term is one year[^1].\nFees apply[^e1]\n", docs[0].PageContent)
+	assert.Equal(t, "[^1]: Renewable.\n[^e1]: See annex.\n", docs[1].PageContent)
+
+	docs = load()
+	require.Len(t, docs, 1)
+	assert.NotContains(t, docs[0].PageContent, "Renewable")
+}
+
+func TestDocxRevisions(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"word/document.xml", `<w:document xmlns:w="w"><w:body>`+
+			`<w:p><w:r><w:t xml:space="preserve">Payment within </w:t></w:r>`+
+			`<w:del w:author="Legal"><w:r><w:delText>30</w:delText></w:r></w:del>`+
+			`<w:ins w:author="Legal"><w:r><w:t>60</w:t></w:r></w:ins>`+
+			`<w:r><w:t xml:space="preserve"> days.</w:t></w:r></w:p>`+
+			`</w:body></w:document>`,
+	)
+	load := func(opts ...DocxOptions) string {
+		docs, err := NewDocx(bytes.NewReader(data), int64(len(data)), opts...).Load(context.Background())
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		return docs[0].PageContent
+	}
+
+	assert.Equal(t, "Payment within 60 days.\n", load())
+	assert.Equal(t, "Payment within 60 days.\n", load(DocxWithRevisions(DocxRevisionsAccept)))
+	assert.Equal(t, "Payment within 30 days.\n", load(DocxWithRevisions(DocxRevisionsReject)))
+	assert.Equal(t, "Payment within {-30-}{+60+} days.\n", load(DocxWithRevisions(DocxRevisionsShow)))
+	assert.Equal(t, "Payment within 60 days.", load(DocxWithMarkdown(true)))
+}
+
+func TestDocxPages(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"word/document.xml", `<w:document xmlns:w="w"><w:body>`+
+			`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>One</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t>First</w:t></w:r><w:r><w:br w:type="page"/></w:r></w:p>`+
+			`<w:p><w:r><w:lastRenderedPageBreak/><w:t>Second</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t xml:space="preserve">Long </w:t></w:r><w:r><w:lastRenderedPageBreak/><w:t>paragraph</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:pageBreakBefore/></w:pPr><w:r><w:t>Fourth</w:t></w:r></w:p>`+
+			`</w:body></w:document>`,
+	)
+
+	docs, err := NewDocx(bytes.NewReader(data), int64(len(data)), DocxWithPages(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 4)
+	assert.Equal(t, schema.Document{PageContent: "One\nFirst\n", Metadata: map[string]any{"page": 1, "total_pages": 4}}, docs[0])
+	assert.Equal(t, "Second\nLong \n", docs[1].PageContent)
+	assert.Equal(t, "paragraph\n", docs[2].PageContent)
+	assert.Equal(t, schema.Document{PageContent: "Fourth\n", Metadata: map[string]any{"page": 4, "total_pages": 4}}, docs[3])
+
+	docs, err = NewDocx(bytes.NewReader(data), int64(len(data)), DocxWithPages(true), DocxWithMarkdown(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 4)
+	assert.Equal(t, "# One\n\nFirst", docs[0].PageContent)
+	assert.Equal(t, "Second\n\nLong", docs[1].PageContent)
+}
+
+func TestDocxProperties(t *testing.T) {
+	t.Parallel()
+
+	workbook := excelize.NewFile()
+	require.NoError(t, workbook.SetCellValue("Sheet1", "A1", "Budget"))
+	require.NoError(t, workbook.SetCellValue("Sheet1", "B1", 1200))
+	sheet, err := workbook.WriteToBuffer()
+	require.NoError(t, err)
+
+	data := zipBytes(t,
+		"word/document.xml", `<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>See the budget</w:t></w:r></w:p></w:body></w:document>`,
+		"word/_rels/document.xml.rels", `<Relationships>`+
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.emf"/>`+
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/package" Target="embeddings/Microsoft_Excel_Worksheet.xlsx"/>`+
+			`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/oleObject" Target="embeddings/oleObject1.bin"/>`+
+			`<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com" TargetMode="External"/>`+
+			`</Relationships>`,
+		"word/media/image1.emf", "emf",
+		"word/embeddings/Microsoft_Excel_Worksheet.xlsx", sheet.String(),
+		"word/embeddings/oleObject1.bin", "ole",
+		"docProps/core.xml", `<cp:coreProperties xmlns:cp="cp" xmlns:dc="dc" xmlns:dcterms="dcterms">`+
+			`<dc:title>Plan</dc:title><dc:creator>Ann</dc:creator><cp:lastModifiedBy>Bob</cp:lastModifiedBy>`+
+			`<cp:revision>7</cp:revision><dcterms:created>2024-03-01T10:00:00Z</dcterms:created>`+
+			`</cp:coreProperties>`,
+		"docProps/app.xml", `<Properties><Words>3</Words><Pages>1</Pages></Properties>`,
+	)
+
+	docs, err := NewDocx(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, map[string]any{
+		"paragraph":        0,
+		"total_paragraph":  1,
+		"title":            "Plan",
+		"author":           "Ann",
+		"last_modified_by": "Bob",
+		"revision":         7,
+		"creation_date":    "2024-03-01T10:00:00Z",
+		"word_count":       3,
+		"embedded_objects": []string{"word/embeddings/Microsoft_Excel_Worksheet.xlsx", "word/embeddings/oleObject1.bin", "word/media/image1.emf"},
+	}, docs[0].Metadata)
+
+	docs, err = NewDocx(bytes.NewReader(data), int64(len(data)), DocxWithEmbedded(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Budget\t1200\t\n", docs[1].PageContent)
+	assert.Equal(t, "word/embeddings/Microsoft_Excel_Worksheet.xlsx", docs[1].Metadata["embedded"])
+	assert.Equal(t, "Sheet1", docs[1].Metadata["sheet_name"])
+	assert.Equal(t, "Plan", docs[1].Metadata["title"])
+}
+
+func TestDocxBrokenProperties(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"word/document.xml", `<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>Still here</w:t></w:r></w:p></w:body></w:document>`,
+		"word/_rels/document.xml.rels", `<Relationships><Relationship Id="rId1"`,
+		"docProps/core.xml", `<cp:coreProperties><dc:title>Plan`,
+	)
+
+	docs, err := NewDocx(bytes.NewReader(data), int64(len(data)), DocxWithEmbedded(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Still here\n", docs[0].PageContent)
+	assert.Equal(t, map[string]any{"paragraph": 0, "total_paragraph": 1}, docs[0].Metadata)
+}
+
+func TestDocxTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// the document part claims to be larger than the limit of the parts
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	body := []byte(`<w:document xmlns:w="w"><w:body/></w:document>`)
+	f, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "word/document.xml",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(body),
+		CompressedSize64:   uint64(len(body)),
+		UncompressedSize64: 2 << 30,
+	})
+	require.NoError(t, err)
+	_, err = f.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	data := buf.Bytes()
+
+	_, err = NewDocx(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.ErrorIs(t, err, ErrTooLarge)
+}
@@ -0,0 +1,93 @@
+package loaders
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"loader/cfb"
+	"loader/docx"
+	"loader/schema"
+)
+
+// The kinds of the errors returned by the loaders, use errors.Is to check them.
+var (
+	// ErrUnsupportedType is returned when there is no loader for the file type.
+	ErrUnsupportedType = errors.New("unsupported file type")
+	// ErrEncrypted is returned when the document is encrypted and no password is given.
+	ErrEncrypted = errors.New("document is encrypted")
+	// ErrBadPassword is returned when the password of the document is not correct.
+	ErrBadPassword = errors.New("wrong password")
+	// ErrCorrupt is returned when the document can not be parsed.
+	ErrCorrupt = errors.New("document is corrupt")
+	// ErrEmptyDocument is returned when no text is found in the document.
+	ErrEmptyDocument = errors.New("document has no text")
+	// ErrTooLarge is returned when the file exceeds the size limit.
+	ErrTooLarge = errors.New("file is too large")
+)
+
+// Error is an error of one of the kinds above, with the original error as the cause.
+type Error struct {
+	Kind  error
+	Cause error
+}
+
+// NewError returns an error of the kind caused by cause.
+func NewError(kind error, cause error) error {
+	if cause == nil {
+		return kind
+	}
+	return &Error{Kind: kind, Cause: cause}
+}
+
+// Error returns the message of the kind followed by the message of the cause.
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Cause.Error()
+}
+
+// Unwrap returns both the kind and the cause, so errors.Is matches either of them.
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Cause}
+}
+
+// isOLE checks the signature of the compound file binary format at the beginning of r,
+// the container of the encrypted office documents.
+func isOLE(r io.ReaderAt) bool {
+	head := make([]byte, len(cfb.Magic))
+	n, _ := r.ReadAt(head, 0)
+	return cfb.IsCompound(head[:n])
+}
+
+// openError returns the error of an office document failed to open, the encrypted documents
+// are stored in compound files instead of zip containers.
+func openError(r io.ReaderAt, err error) error {
+	if errors.Is(err, docx.ErrTooLarge) {
+		return NewError(ErrTooLarge, err)
+	}
+	if isOLE(r) {
+		return NewError(ErrEncrypted, err)
+	}
+	return NewError(ErrCorrupt, err)
+}
+
+// compoundError returns the error of a legacy office document failed to read from its compound file
+func compoundError(err error) error {
+	if errors.Is(err, cfb.ErrEncrypted) {
+		return NewError(ErrEncrypted, err)
+	}
+	return NewError(ErrCorrupt, err)
+}
+
+// HasContent reports whether any of the documents has text other than white spaces or is the placeholder
+// of an image only page, the scanned documents are then returned to be passed to OCR instead of failing without text.
+func HasContent(docs []schema.Document) bool {
+	for _, doc := range docs {
+		if strings.TrimSpace(doc.PageContent) != "" {
+			return true
+		}
+		if imageOnly, _ := doc.Metadata["image_only"].(bool); imageOnly {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestLoaderErrors(t *testing.T) {
+	t.Parallel()
+
+	open := func(t *testing.T, name string) (*os.File, int64) {
+		t.Helper()
+		f, err := os.Open("../yaoapp/data/" + name)
+		require.NoError(t, err)
+		t.Cleanup(func() { f.Close() })
+		finfo, err := f.Stat()
+		require.NoError(t, err)
+		return f, finfo.Size()
+	}
+
+	t.Run("PDFEncrypted", func(t *testing.T) {
+		t.Parallel()
+		f, size := open(t, "sample_password.pdf")
+		_, err := NewPDF(f, size).Load(context.Background())
+		require.ErrorIs(t, err, ErrEncrypted)
+		require.ErrorIs(t, err, pdf.ErrInvalidPassword)
+	})
+
+	t.Run("PDFBadPassword", func(t *testing.T) {
+		t.Parallel()
+		f, size := open(t, "sample_password.pdf")
+		_, err := NewPDF(f, size, PdfWithPassword("password1")).Load(context.Background())
+		require.ErrorIs(t, err, ErrBadPassword)
+	})
+
+	t.Run("PDFCorrupt", func(t *testing.T) {
+		t.Parallel()
+		data := []byte("%PDF-1.4\nnot really a pdf")
+		_, err := NewPDF(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+		require.ErrorIs(t, err, ErrCorrupt)
+	})
+
+	t.Run("XLSXEncrypted", func(t *testing.T) {
+		t.Parallel()
+		f, _ := open(t, "test_password.xlsx")
+		_, err := NewExcelx(f).Load(context.Background())
+		require.ErrorIs(t, err, ErrEncrypted)
+	})
+
+	t.Run("XLSXBadPassword", func(t *testing.T) {
+		t.Parallel()
+		f, _ := open(t, "test_password.xlsx")
+		_, err := NewExcelx(f, excelize.Options{Password: "wrong"}).Load(context.Background())
+		require.ErrorIs(t, err, ErrBadPassword)
+	})
+
+	t.Run("DocxCorrupt", func(t *testing.T) {
+		t.Parallel()
+		data := []byte("PK\x03\x04 truncated")
+		_, err := NewDocx(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+		require.ErrorIs(t, err, ErrCorrupt)
+	})
+
+	t.Run("HTMLEmpty", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewHTML(bytes.NewReader(nil)).Load(context.Background())
+		require.ErrorIs(t, err, ErrEmptyDocument)
+	})
+}
+
+func TestError(t *testing.T) {
+	t.Parallel()
+
+	cause := os.ErrClosed
+	err := NewError(ErrCorrupt, cause)
+	assert.ErrorIs(t, err, ErrCorrupt)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "document is corrupt: "+cause.Error(), err.Error())
+	assert.Equal(t, ErrCorrupt, NewError(ErrCorrupt, nil))
+}
@@ -2,6 +2,7 @@ package loaders
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"loader/schema"
@@ -22,7 +23,10 @@ func NewHTML(r io.Reader) HTML {
 }
 
 // Load reads from the io.Reader and returns a single document with the data.
-func (h HTML) Load(_ context.Context) ([]schema.Document, error) {
+func (h HTML) Load(ctx context.Context) ([]schema.Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	// doc, err := goquery.NewDocumentFromReader(h.r)
 	// if err != nil {
 	// 	return nil, err
@@ -38,8 +42,11 @@ func (h HTML) Load(_ context.Context) ([]schema.Document, error) {
 	// sanitized := bluemonday.UGCPolicy().Sanitize(sel.Text())
 	// pagecontent := strings.TrimSpace(sanitized)
 	document, err := utils.GetHtmlText(h.r)
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmptyDocument
+	}
 	if err != nil {
-		return nil, err
+		return nil, NewError(ErrCorrupt, err)
 	}
 	return []schema.Document{
 		{
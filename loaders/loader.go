@@ -2,6 +2,7 @@ package loaders
 
 import (
 	"context"
+	"iter"
 
 	"loader/schema"
 	"loader/textsplitter"
@@ -9,8 +10,49 @@ import (
 
 // Loader is the interface for loading and splitting documents from a source.
 type Loader interface {
-	// Load loads from a source and returns documents.
+	// Load loads from a source and returns documents. When the context is done, Load stops
+	// between pages, sheets, slides or rows and returns the documents loaded so far with the context error.
 	Load(ctx context.Context) ([]schema.Document, error)
 	// LoadAndSplit loads from a source and splits the documents using a text splitter.
 	LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error)
 }
+
+// IterLoader is implemented by the loaders that produce the documents one by one,
+// so large files can be processed without holding all the documents in memory.
+type IterLoader interface {
+	Loader
+	// LoadIter returns the documents as they are loaded, the iteration ends after the first error.
+	// When the context is done the context error is yielded.
+	LoadIter(ctx context.Context) iter.Seq2[schema.Document, error]
+}
+
+// collect returns all the documents of the iterator. When the context is done the documents
+// loaded so far are returned with the context error, they are discarded on the other errors.
+func collect(ctx context.Context, seq iter.Seq2[schema.Document, error]) ([]schema.Document, error) {
+	docs := []schema.Document{}
+	for doc, err := range seq {
+		if err != nil {
+			if ctx.Err() != nil {
+				return docs, err
+			}
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// yieldAll yields the documents until yield returns false, the context error is yielded when the context is done.
+// It returns false when the iteration is stopped.
+func yieldAll(ctx context.Context, docs []schema.Document, yield func(schema.Document, error) bool) bool {
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			yield(schema.Document{}, err)
+			return false
+		}
+		if !yield(doc, nil) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,24 +1,36 @@
 package loaders
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"loader/schema"
+	"loader/textsplitter"
+	"loader/utils"
 )
 
+// notionIDRegexp matches the id suffix Notion appends to the exported file names,
+// e.g. "Getting Started 1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d.md"
+var notionIDRegexp = regexp.MustCompile(`^(.*?)\s+([0-9a-fA-F]{32}|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})$`)
+
 // NotionDirectoryLoader is a document loader that reads content from pages within a Notion Database.
+// It reads the markdown pages and the csv databases of a Notion export, sub-pages are read recursively.
 type NotionDirectoryLoader struct {
 	filePath string
 	encoding string
 }
 
+var _ Loader = (*NotionDirectoryLoader)(nil)
+
 // NewNotionDirectory creates a new NotionDirectoryLoader with the given file path and encoding.
 func NewNotionDirectory(filePath string, encoding ...string) *NotionDirectoryLoader {
 	defaultEncoding := "utf-8"
 
-	if len(encoding) > 0 {
+	if len(encoding) > 0 && encoding[0] != "" {
 		return &NotionDirectoryLoader{
 			filePath: filePath,
 			encoding: encoding[0],
@@ -31,31 +43,141 @@ func NewNotionDirectory(filePath string, encoding ...string) *NotionDirectoryLoa
 	}
 }
 
+// parseNotionName splits the exported file or directory name into the page title and the Notion id.
+func parseNotionName(name string) (title string, id string) {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.TrimSuffix(name, "_all")
+	matches := notionIDRegexp.FindStringSubmatch(name)
+	if matches == nil {
+		return name, ""
+	}
+	return matches[1], strings.ReplaceAll(strings.ToLower(matches[2]), "-", "")
+}
+
 // Load retrieves data from a Notion directory and returns a list of schema.Document objects.
-func (n *NotionDirectoryLoader) Load() ([]schema.Document, error) {
-	files, err := os.ReadDir(n.filePath)
+func (n *NotionDirectoryLoader) Load(ctx context.Context) ([]schema.Document, error) {
+	return n.loadDir(ctx, n.filePath, "")
+}
+
+// loadDir loads the pages of the directory, and then the sub-pages in the sub directories
+func (n *NotionDirectoryLoader) loadDir(ctx context.Context, dir string, parentID string) ([]schema.Document, error) {
+	files, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
 
+	names := map[string]bool{}
+	for _, file := range files {
+		names[file.Name()] = true
+	}
+
 	documents := make([]schema.Document, 0, len(files))
+	subdirs := []string{}
 
 	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return documents, err
+		}
+		if file.IsDir() {
+			subdirs = append(subdirs, file.Name())
+			continue
+		}
+
 		ext := filepath.Ext(strings.ToLower(file.Name()))
+		filePath := filepath.Join(dir, file.Name())
+		title, id := parseNotionName(file.Name())
+		metadata := map[string]any{"source": filePath, "title": title}
+		if id != "" {
+			metadata["notion_id"] = id
+		}
+		if parentID != "" {
+			metadata["parent_id"] = parentID
+		}
 
-		if file.IsDir() || (ext != ".md" && ext != ".mdx") {
-			continue
+		switch ext {
+		case ".md", ".mdx":
+			text, err := n.readFile(filePath)
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, schema.Document{PageContent: text, Metadata: metadata})
+
+		case ".csv":
+			// newer exports contain both "name.csv" and "name_all.csv", the latter has all the rows
+			base := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+			if names[base+"_all"+filepath.Ext(file.Name())] {
+				continue
+			}
+			rows, err := n.loadDatabase(ctx, filePath)
+			if err != nil {
+				if ctx.Err() != nil {
+					return documents, err
+				}
+				return nil, err
+			}
+			for _, row := range rows {
+				for key, value := range metadata {
+					row.Metadata[key] = value
+				}
+				row.Metadata["database"] = title
+				documents = append(documents, row)
+			}
 		}
+	}
 
-		filePath := filepath.Join(n.filePath, file.Name())
-		text, err := os.ReadFile(filePath)
+	for _, name := range subdirs {
+		_, id := parseNotionName(name)
+		if id == "" {
+			id = parentID
+		}
+		docs, err := n.loadDir(ctx, filepath.Join(dir, name), id)
+		documents = append(documents, docs...)
 		if err != nil {
-			return nil, err
+			return documents, err
 		}
-
-		metadata := map[string]interface{}{"source": filePath}
-		documents = append(documents, schema.Document{PageContent: string(text), Metadata: metadata})
 	}
 
 	return documents, nil
 }
+
+// readFile reads the file content and converts it from the loader encoding
+func (n *NotionDirectoryLoader) readFile(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	r, err := utils.NewEncodingReader(f, n.encoding)
+	if err != nil {
+		return "", err
+	}
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(text), nil
+}
+
+// loadDatabase converts the rows of an exported database with the csv loader
+func (n *NotionDirectoryLoader) loadDatabase(ctx context.Context, filePath string) ([]schema.Document, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := utils.NewEncodingReader(f, n.encoding)
+	if err != nil {
+		return nil, err
+	}
+	return NewCSV(r).Load(ctx)
+}
+
+// LoadAndSplit reads the Notion pages and splits them into multiple
+// documents using a text splitter, the markdown splitter is recommended.
+func (n *NotionDirectoryLoader) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := n.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
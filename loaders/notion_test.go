@@ -1,6 +1,7 @@
 package loaders
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -30,7 +31,7 @@ func TestNotionDirectoryLoader_Load(t *testing.T) {
 			content: "# Test Document 1\nThis is test document 1.",
 			expected: schema.Document{
 				PageContent: "# Test Document 1\nThis is test document 1.",
-				Metadata:    map[string]interface{}{"source": filepath.Join(tempDir, "test1.md")},
+				Metadata:    map[string]interface{}{"source": filepath.Join(tempDir, "test1.md"), "title": "test1"},
 			},
 		},
 		{
@@ -38,7 +39,7 @@ func TestNotionDirectoryLoader_Load(t *testing.T) {
 			content: "# Test Document 2\nThis is test document 2.",
 			expected: schema.Document{
 				PageContent: "# Test Document 2\nThis is test document 2.",
-				Metadata:    map[string]interface{}{"source": filepath.Join(tempDir, "test2.md")},
+				Metadata:    map[string]interface{}{"source": filepath.Join(tempDir, "test2.md"), "title": "test2"},
 			},
 		},
 	}
@@ -53,7 +54,7 @@ func TestNotionDirectoryLoader_Load(t *testing.T) {
 	loader := NewNotionDirectory(tempDir)
 
 	// Load documents from the test directory
-	docs, err := loader.Load()
+	docs, err := loader.Load(context.Background())
 	require.NoError(t, err)
 
 	// Verify the loaded documents match the expected ones
@@ -62,3 +63,65 @@ func TestNotionDirectoryLoader_Load(t *testing.T) {
 		assert.Equal(t, expected.expected, docs[i])
 	}
 }
+
+func TestNotionDirectoryLoader_LoadExport(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	pageID := "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d"
+	subID := "0123456789abcdef0123456789abcdef"
+	dbID := "fedcba9876543210fedcba9876543210"
+
+	files := map[string]string{
+		"Home " + pageID + ".md":                                          "# Home\nWelcome.",
+		"Home " + pageID + "/Sub Page " + subID + ".md":                   "# Sub Page\nDetails.",
+		"Home " + pageID + "/Tasks " + dbID + ".csv":                      "Name,Status\nWrite,Done\n",
+		"Home " + pageID + "/Tasks " + dbID + "_all.csv":                  "\ufeffName,Status\nWrite,Done\nReview,Todo\n",
+		"Home " + pageID + "/Sub Page " + subID + "/image.png":            "png",
+		"Home " + pageID + "/Sub Page " + subID + "/Leaf " + dbID + ".md": "Leaf page.",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(tempDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o700))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+	}
+
+	docs, err := NewNotionDirectory(tempDir).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 5)
+
+	assert.Equal(t, "# Home\nWelcome.", docs[0].PageContent)
+	assert.Equal(t, "Home", docs[0].Metadata["title"])
+	assert.Equal(t, pageID, docs[0].Metadata["notion_id"])
+	assert.NotContains(t, docs[0].Metadata, "parent_id")
+
+	assert.Equal(t, "# Sub Page\nDetails.", docs[1].PageContent)
+	assert.Equal(t, "Sub Page", docs[1].Metadata["title"])
+	assert.Equal(t, pageID, docs[1].Metadata["parent_id"])
+
+	assert.Equal(t, "Name: Write\nStatus: Done", docs[2].PageContent)
+	assert.Equal(t, "Tasks", docs[2].Metadata["database"])
+	assert.Equal(t, dbID, docs[2].Metadata["notion_id"])
+	assert.Equal(t, 1, docs[2].Metadata["row"])
+	assert.Equal(t, "Name: Review\nStatus: Todo", docs[3].PageContent)
+
+	assert.Equal(t, "Leaf page.", docs[4].PageContent)
+	assert.Equal(t, "Leaf", docs[4].Metadata["title"])
+	assert.Equal(t, subID, docs[4].Metadata["parent_id"])
+}
+
+func TestParseNotionName(t *testing.T) {
+	t.Parallel()
+
+	title, id := parseNotionName("Getting Started 1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d.md")
+	assert.Equal(t, "Getting Started", title)
+	assert.Equal(t, "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d", id)
+
+	title, id = parseNotionName("Tasks 1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d_all.csv")
+	assert.Equal(t, "Tasks", title)
+	assert.Equal(t, "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d", id)
+
+	title, id = parseNotionName("readme.md")
+	assert.Equal(t, "readme", title)
+	assert.Empty(t, id)
+}
@@ -0,0 +1,142 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// OCRProvider recognizes the text of the images found in the documents, like the scanned PDF pages
+// and the pictures of the DOCX and PPTX files.
+type OCRProvider interface {
+	// Recognize returns the text of the image, mime is the type of the image data, e.g. "image/png".
+	Recognize(ctx context.Context, image []byte, mime string) (string, error)
+}
+
+// OCRFunc is a function used as an OCRProvider, e.g. a stub in the tests.
+type OCRFunc func(ctx context.Context, image []byte, mime string) (string, error)
+
+// Recognize calls f.
+func (f OCRFunc) Recognize(ctx context.Context, image []byte, mime string) (string, error) {
+	return f(ctx, image, mime)
+}
+
+// imagePlaceholder is replaced by the path of the image file in the arguments of CommandOCR
+const imagePlaceholder = "{image}"
+
+// imageExtensions are the extensions of the temporary image files of CommandOCR
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/jp2":  ".jp2",
+	"image/gif":  ".gif",
+	"image/bmp":  ".bmp",
+	"image/tiff": ".tif",
+}
+
+// CommandOCR recognizes the images with a local command printing the text to stdout, like tesseract.
+// The image is written to a temporary file whose path replaces "{image}" in the arguments,
+// it is written to stdin when there is no "{image}" argument.
+type CommandOCR struct {
+	Command string
+	Args    []string
+}
+
+var _ OCRProvider = CommandOCR{}
+
+// NewCommandOCR creates an OCR provider running the command with the arguments.
+func NewCommandOCR(command string, args ...string) CommandOCR {
+	return CommandOCR{Command: command, Args: args}
+}
+
+// DefaultOCR returns the OCR provider running tesseract, the languages are the tesseract languages
+// separated by "+", e.g. "chi_sim+eng", the tesseract default is used when it is empty.
+func DefaultOCR(languages string) CommandOCR {
+	args := []string{imagePlaceholder, "stdout"}
+	if languages != "" {
+		args = append(args, "-l", languages)
+	}
+	return NewCommandOCR("tesseract", args...)
+}
+
+// Recognize runs the command and returns its output.
+func (c CommandOCR) Recognize(ctx context.Context, image []byte, mime string) (string, error) {
+	args := make([]string, len(c.Args))
+	path := ""
+	for i, arg := range c.Args {
+		if strings.Contains(arg, imagePlaceholder) && path == "" {
+			f, err := os.CreateTemp("", "ocr-*"+imageExtensions[mime])
+			if err != nil {
+				return "", err
+			}
+			path = f.Name()
+			defer os.Remove(path)
+			_, err = f.Write(image)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+		args[i] = strings.ReplaceAll(arg, imagePlaceholder, path)
+	}
+
+	cmd := exec.CommandContext(ctx, c.Command, args...)
+	if path == "" {
+		cmd.Stdin = bytes.NewReader(image)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("%s: %w: %s", c.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// recognizeImages returns the texts recognized in the images separated by blank lines,
+// the images without text are left out.
+func recognizeImages(ctx context.Context, ocr OCRProvider, images []imageFile) (string, error) {
+	texts := []string{}
+	for _, image := range images {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		text, err := ocr.Recognize(ctx, image.data, image.mime)
+		if err != nil {
+			return "", fmt.Errorf("ocr %s: %w", image.name, err)
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// imageFile is the data of an image to recognize
+type imageFile struct {
+	name string
+	mime string
+	data []byte
+}
+
+// imageMIME returns the mime type of the image file from its extension, it is empty for the unknown types
+func imageMIME(name string) string {
+	ext := strings.ToLower(name[strings.LastIndex(name, ".")+1:])
+	switch ext {
+	case "jpg", "jpeg":
+		return "image/jpeg"
+	case "tif", "tiff":
+		return "image/tiff"
+	case "png", "gif", "bmp":
+		return "image/" + ext
+	}
+	return ""
+}
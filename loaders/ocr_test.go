@@ -0,0 +1,151 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubOCR returns an OCR provider recognizing the images as their mime type and size
+func stubOCR() OCRFunc {
+	return func(ctx context.Context, image []byte, mime string) (string, error) {
+		return fmt.Sprintf("%s %d", mime, len(image)), nil
+	}
+}
+
+func TestCommandOCR(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	text, err := NewCommandOCR("sh", "-c", `cat "$0"; case "$0" in *.png) echo " png";; esac`, "{image}").
+		Recognize(context.Background(), []byte("from file"), "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, "from file png", text)
+
+	text, err = NewCommandOCR("sh", "-c", "cat").Recognize(context.Background(), []byte(" from stdin\n"), "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, "from stdin", text)
+
+	_, err = NewCommandOCR("sh", "-c", "echo failed >&2; exit 1").Recognize(context.Background(), nil, "image/png")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed")
+}
+
+func TestPDFOCR(t *testing.T) {
+	t.Parallel()
+
+	var jpg bytes.Buffer
+	require.NoError(t, jpeg.Encode(&jpg, image.NewGray(image.Rect(0, 0, 8, 8)), nil))
+
+	// the first page is a JPEG image, the second page raw gray samples, the third page has text
+	page := "<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Im1 %d 0 R >> >> /Contents 6 0 R >>"
+	data := buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>",
+		fmt.Sprintf(page, 7), fmt.Sprintf(page, 8),
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 9 0 R >> >> /Contents 10 0 R >>",
+		pdfStream("q 612 0 0 792 0 0 cm /Im1 Do Q"),
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width 8 /Height 8 /ColorSpace /DeviceGray /BitsPerComponent 8 "+
+			"/Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream", jpg.Len(), jpg.String()),
+		"<< /Type /XObject /Subtype /Image /Width 2 /Height 2 /ColorSpace /DeviceGray /BitsPerComponent 8 /Length 4 >>\n"+
+			"stream\n\x00\xff\xff\x00\nendstream",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		pdfText("Text"),
+	)
+
+	images := map[string][]byte{}
+	ocr := OCRFunc(func(ctx context.Context, image []byte, mime string) (string, error) {
+		images[mime] = image
+		return "recognized " + mime, nil
+	})
+	docs, err := NewPDF(bytes.NewReader(data), int64(len(data)), PdfWithOCR(ocr)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+
+	assert.Equal(t, "recognized image/jpeg\n", docs[0].PageContent)
+	assert.Equal(t, true, docs[0].Metadata["ocr"])
+	assert.Equal(t, jpg.Bytes(), images["image/jpeg"])
+
+	assert.Equal(t, "recognized image/png\n", docs[1].PageContent)
+	img, err := png.Decode(bytes.NewReader(images["image/png"]))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 2, 2), img.Bounds())
+	assert.Equal(t, color.Gray{0xff}, color.GrayModel.Convert(img.At(1, 0)))
+	assert.Equal(t, color.Gray{0}, color.GrayModel.Convert(img.At(0, 0)))
+
+	assert.Equal(t, "Text\n", docs[2].PageContent)
+	assert.NotContains(t, docs[2].Metadata, "ocr")
+	assert.Equal(t, []int{1, 2}, docs[2].Metadata["image_pages"])
+
+	failing := OCRFunc(func(ctx context.Context, image []byte, mime string) (string, error) {
+		return "", errors.New("no engine")
+	})
+	_, err = NewPDF(bytes.NewReader(data), int64(len(data)), PdfWithOCR(failing)).Load(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "page 1")
+}
+
+func TestDocxOCR(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"word/document.xml", `<w:document xmlns:w="w" xmlns:a="a" xmlns:pic="pic" xmlns:wp="wp" xmlns:r="r"><w:body>`+
+			`<w:p><w:r><w:t>Before the chart</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:drawing><wp:inline><a:graphic><a:graphicData><pic:pic><pic:blipFill>`+
+			`<a:blip r:embed="rId5"/></pic:blipFill></pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>`+
+			`<w:p><w:r><w:t>After</w:t></w:r></w:p>`+
+			`</w:body></w:document>`,
+		"word/_rels/document.xml.rels", `<Relationships>`+
+			`<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/>`+
+			`</Relationships>`,
+		"word/media/image1.png", "png data",
+	)
+
+	docs, err := NewDocx(bytes.NewReader(data), int64(len(data)), DocxWithOCR(stubOCR())).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Before the chart\nimage/png 8\n", docs[0].PageContent)
+	assert.Equal(t, true, docs[0].Metadata["ocr"])
+	assert.Equal(t, "After\n", docs[1].PageContent)
+	assert.NotContains(t, docs[1].Metadata, "ocr")
+
+	docs, err = NewDocx(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Before the chart\n", docs[0].PageContent)
+}
+
+func TestPPTXOCR(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"ppt/slides/slide1.xml", `<p:sld xmlns:p="p" xmlns:a="a"><a:p><a:r><a:t>Title</a:t></a:r></a:p></p:sld>`,
+		"ppt/slides/slide2.xml", `<p:sld xmlns:p="p"></p:sld>`,
+		"ppt/slides/_rels/slide2.xml.rels", `<Relationships>`+
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="../media/image1.jpeg"/>`+
+			`</Relationships>`,
+		"ppt/media/image1.jpeg", "jpeg",
+	)
+
+	docs, err := NewPPTX(bytes.NewReader(data), int64(len(data)), PptxWithOCR(stubOCR())).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "\nTitle", docs[0].PageContent)
+	assert.Equal(t, "\nimage/jpeg 4\n", docs[1].PageContent)
+	assert.Equal(t, true, docs[1].Metadata["ocr"])
+
+	docs, err = NewPPTX(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+}
@@ -0,0 +1,149 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// odfContent returns content.xml with the body of the document
+func odfContent(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"` +
+		` xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"` +
+		` xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"` +
+		` xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"` +
+		` xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0"` +
+		` xmlns:presentation="urn:oasis:names:tc:opendocument:xmlns:presentation:1.0">` +
+		`<office:automatic-styles><style:style style:name="dp1" style:family="drawing-page"/>` +
+		`<style:style style:name="dp2" style:family="drawing-page"><style:drawing-page-properties presentation:visibility="hidden"/></style:style>` +
+		`</office:automatic-styles><office:body>` + body + `</office:body></office:document-content>`
+}
+
+func TestODT(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"mimetype", "application/vnd.oasis.opendocument.text",
+		"content.xml", odfContent(`<office:text><text:sequence-decls><text:sequence-decl text:name="Table"/></text:sequence-decls>`+
+			`<text:h text:outline-level="1">Report</text:h>`+
+			`<text:p>First <text:span>line</text:span><text:s text:c="2"/>end<text:note><text:note-citation>1</text:note-citation>`+
+			`<text:note-body><text:p>note</text:p></text:note-body></text:note></text:p>`+
+			`<text:list><text:list-item><text:p>one</text:p><text:list><text:list-item><text:p>nested</text:p></text:list-item></text:list></text:list-item></text:list>`+
+			`<text:p/>`+
+			`<text:section><text:h text:outline-level="2">Data</text:h></text:section>`+
+			`<table:table table:name="T"><table:table-column table:number-columns-repeated="3"/>`+
+			`<table:table-header-rows><table:table-row><table:table-cell><text:p>Name</text:p></table:table-cell>`+
+			`<table:table-cell table:number-columns-spanned="2"><text:p>Score</text:p></table:table-cell><table:covered-table-cell/></table:table-row></table:table-header-rows>`+
+			`<table:table-row><table:table-cell><text:p>Alice</text:p></table:table-cell><table:table-cell><text:p>90</text:p></table:table-cell>`+
+			`<table:table-cell><text:p>A</text:p></table:table-cell></table:table-row></table:table>`+
+			`<text:p>After<draw:frame><draw:text-box><text:p>boxed</text:p></draw:text-box></draw:frame></text:p>`+
+			`</office:text>`))
+
+	docs, err := NewODT(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 4)
+	assert.Equal(t, "Report\nFirst line  end\none\nnested\n", docs[0].PageContent)
+	assert.Equal(t, "Data\n", docs[1].PageContent)
+	assert.Equal(t, "Name: Alice; Score: 90; Score: A\n", docs[2].PageContent)
+	assert.Equal(t, "After\nboxed\n", docs[3].PageContent)
+	assert.Equal(t, map[string]any{"paragraph": 3, "total_paragraph": 4}, docs[3].Metadata)
+
+	docs, err = NewODT(bytes.NewReader(data), int64(len(data)), OdtWithMarkdown(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "# Report\n\nFirst line  end\n\n- one\n  - nested\n\n## Data\n\n"+
+		"| Name | Score | Score |\n| --- | --- | --- |\n| Alice | 90 | A |\n\nAfter\n\nboxed", docs[0].PageContent)
+
+	encrypted := zipBytes(t,
+		"mimetype", "application/vnd.oasis.opendocument.text",
+		"META-INF/manifest.xml", `<manifest:manifest xmlns:manifest="m"><manifest:file-entry manifest:full-path="content.xml"><manifest:encryption-data/></manifest:file-entry></manifest:manifest>`,
+		"content.xml", "\x01\x02")
+	_, err = NewODT(bytes.NewReader(encrypted), int64(len(encrypted))).Load(context.Background())
+	require.ErrorIs(t, err, ErrEncrypted)
+}
+
+func TestODS(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"mimetype", "application/vnd.oasis.opendocument.spreadsheet",
+		"content.xml", odfContent(`<office:spreadsheet>`+
+			`<table:table table:name="数据"><table:table-column table:number-columns-repeated="1024"/>`+
+			`<table:table-row><table:table-cell office:value-type="string"><text:p>Name</text:p></table:table-cell>`+
+			`<table:table-cell table:number-columns-repeated="2"><text:p>x</text:p></table:table-cell>`+
+			`<table:table-cell table:number-columns-repeated="1021"/></table:table-row>`+
+			`<table:table-row table:number-rows-repeated="2"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>`+
+			`<table:table-row><table:table-cell/><table:table-cell office:value-type="float" office:value="3.5"/>`+
+			`<table:table-cell table:number-columns-spanned="2"><text:p>merged</text:p></table:table-cell><table:covered-table-cell/></table:table-row>`+
+			`<table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>`+
+			`</table:table>`+
+			`<table:table table:name="Empty"/>`+
+			`</office:spreadsheet>`))
+
+	docs, err := NewODS(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Name\tx\tx\t\n\n\n\t3.5\tmerged\t\t\n", docs[0].PageContent)
+	assert.Equal(t, map[string]any{"shee": 0, "sheet_name": "数据", "total_sheets": 2}, docs[0].Metadata)
+	assert.Equal(t, "", docs[1].PageContent)
+}
+
+func TestODFRepeatedCells(t *testing.T) {
+	t.Parallel()
+
+	// the repeated cell with a value would expand to the whole grid of the sheet
+	data := zipBytes(t,
+		"mimetype", "application/vnd.oasis.opendocument.spreadsheet",
+		"content.xml", odfContent(`<office:spreadsheet><table:table table:name="Bomb">`+
+			`<table:table-row table:number-rows-repeated="1048576">`+
+			`<table:table-cell table:number-columns-repeated="16384" office:value-type="float" office:value="1"/>`+
+			`</table:table-row></table:table></office:spreadsheet>`))
+
+	docs, err := NewODS(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, 1<<20, strings.Count(docs[0].PageContent, "1\t"))
+
+	// the spans are bounded by the cells of the table
+	data = zipBytes(t,
+		"mimetype", "application/vnd.oasis.opendocument.text",
+		"content.xml", odfContent(`<office:text><table:table table:name="T">`+
+			`<table:table-row><table:table-cell table:number-columns-spanned="2147483647" table:number-rows-spanned="2147483647"><text:p>wide</text:p></table:table-cell>`+
+			`<table:covered-table-cell/></table:table-row>`+
+			`<table:table-row table:number-rows-repeated="2"><table:covered-table-cell table:number-columns-repeated="2"/></table:table-row>`+
+			`</table:table></office:text>`))
+
+	docs, err = NewODT(bytes.NewReader(data), int64(len(data)), OdtWithMarkdown(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "| wide | wide |\n| --- | --- |\n| wide | wide |\n| wide | wide |", docs[0].PageContent)
+}
+
+func TestODP(t *testing.T) {
+	t.Parallel()
+
+	data := zipBytes(t,
+		"mimetype", "application/vnd.oasis.opendocument.presentation",
+		"content.xml", odfContent(`<office:presentation>`+
+			`<draw:page draw:name="page1"><draw:frame presentation:class="title"><draw:text-box><text:p>Welcome</text:p></draw:text-box></draw:frame>`+
+			`<draw:frame><draw:text-box><text:list><text:list-item><text:p>Point <text:span>one</text:span></text:p></text:list-item></text:list></draw:text-box></draw:frame>`+
+			`<presentation:notes><draw:frame><draw:text-box><text:p>speaker notes</text:p></draw:text-box></draw:frame></presentation:notes></draw:page>`+
+			`<draw:page draw:name="page2"><draw:frame><draw:image/></draw:frame></draw:page>`+
+			`<draw:page draw:name="Scores" draw:style-name="dp2"><draw:frame><table:table><table:table-row><table:table-cell><text:p>a</text:p></table:table-cell>`+
+			`<table:table-cell><text:p>b</text:p></table:table-cell></table:table-row></table:table></draw:frame></draw:page>`+
+			`</office:presentation>`))
+
+	docs, err := NewODP(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Welcome\nPoint one\n", docs[0].PageContent)
+	assert.Equal(t, map[string]any{"slide": 0, "total_slides": 3, "slide_number": 1, "slide_title": "Welcome"}, docs[0].Metadata)
+	// the second slide has no text, the numbers of the next slides are kept
+	assert.Equal(t, "a | b\n", docs[1].PageContent)
+	assert.Equal(t, map[string]any{"slide": 2, "total_slides": 3, "slide_number": 3, "slide_title": "Scores", "hidden": true}, docs[1].Metadata)
+}
@@ -0,0 +1,88 @@
+package loaders
+
+import (
+	"context"
+	"io"
+	"iter"
+	"regexp"
+
+	"loader/odf"
+	"loader/schema"
+	"loader/textsplitter"
+)
+
+// defaultPageName matches the names given to the slides by the applications, the other names are set by the author
+var defaultPageName = regexp.MustCompile(`^page[0-9]+$`)
+
+// ODP loads the text of the OpenDocument presentations.
+type ODP struct {
+	r io.ReaderAt
+	s int64
+}
+
+var _ IterLoader = ODP{}
+
+// NewODP creates a new odp loader with an io.ReaderAt.
+func NewODP(r io.ReaderAt, size int64) ODP {
+	return ODP{r: r, s: size}
+}
+
+// Load reads from the io.ReaderAt and returns a document for each slide with text.
+func (o ODP) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, o.LoadIter(ctx))
+}
+
+// LoadIter yields a document for each slide with text, the paragraphs of the slide are written one per line.
+// The metadata are the ones of the pptx loader: the position of the slide in the presentation, the text
+// of its title placeholder, or the name of the slide set by the author, and whether the slide is hidden.
+func (o ODP) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(schema.Document{}, err)
+			return
+		}
+		slides, err := odf.ReadSlides(o.r, o.s)
+		if err != nil {
+			yield(schema.Document{}, odfError(err))
+			return
+		}
+
+		docs := make([]schema.Document, 0, len(slides))
+		for _, slide := range slides {
+			text := odf.Text(slide.Blocks)
+			if text == "" {
+				continue
+			}
+			doc := schema.Document{
+				PageContent: text + "\n",
+				Metadata: map[string]any{
+					"slide":        slide.Number - 1,
+					"total_slides": len(slides),
+					"slide_number": slide.Number,
+				},
+			}
+			title := slide.Title
+			if title == "" && !defaultPageName.MatchString(slide.Name) {
+				title = slide.Name
+			}
+			if title != "" {
+				doc.Metadata["slide_title"] = title
+			}
+			if slide.Hidden {
+				doc.Metadata["hidden"] = true
+			}
+			docs = append(docs, doc)
+		}
+		yieldAll(ctx, docs, yield)
+	}
+}
+
+// LoadAndSplit reads text data from the io.ReaderAt and splits it into multiple
+// documents using a text splitter.
+func (o ODP) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := o.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
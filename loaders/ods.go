@@ -0,0 +1,75 @@
+package loaders
+
+import (
+	"context"
+	"io"
+	"iter"
+
+	"loader/odf"
+	"loader/schema"
+	"loader/textsplitter"
+)
+
+// ODS loads the cell values of the OpenDocument spreadsheets.
+type ODS struct {
+	r io.ReaderAt
+	s int64
+}
+
+var _ IterLoader = ODS{}
+
+// NewODS creates a new ods loader with an io.ReaderAt.
+func NewODS(r io.ReaderAt, size int64) ODS {
+	return ODS{r: r, s: size}
+}
+
+// Load reads from the io.ReaderAt and returns a document for each sheet.
+func (o ODS) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, o.LoadIter(ctx))
+}
+
+// LoadIter yields a document for each sheet, the cells are written like the xlsx loader does
+// and the merged cells have their text in the first cell only.
+func (o ODS) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(schema.Document{}, err)
+			return
+		}
+		sheets, err := odf.ReadSheets(o.r, o.s)
+		if err != nil {
+			yield(schema.Document{}, odfError(err))
+			return
+		}
+
+		docs := make([]schema.Document, len(sheets))
+		for i, sheet := range sheets {
+			rows := make([][]string, len(sheet.Rows))
+			for r, row := range sheet.Rows {
+				rows[r] = make([]string, len(row))
+				for c, cell := range row {
+					rows[r][c] = cell.Text
+				}
+			}
+			docs[i] = schema.Document{
+				PageContent: rowsText(rows),
+				Metadata: map[string]any{
+					"shee":         i,
+					"sheet_name":   sheet.Name,
+					"total_sheets": len(sheets),
+				},
+			}
+		}
+		yieldAll(ctx, docs, yield)
+	}
+}
+
+// LoadAndSplit reads text data from the io.ReaderAt and splits it into multiple
+// documents using a text splitter.
+func (o ODS) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := o.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
@@ -0,0 +1,176 @@
+package loaders
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+
+	"loader/odf"
+	"loader/schema"
+	"loader/textsplitter"
+)
+
+// ODT loads the OpenDocument text documents.
+type ODT struct {
+	r        io.ReaderAt
+	s        int64
+	markdown bool
+	tables   string
+}
+
+var _ IterLoader = ODT{}
+
+// ODTOptions are options for the odt loader.
+type ODTOptions func(odt *ODT)
+
+// OdtWithMarkdown converts the document into markdown like DocxWithMarkdown, the headings and the list
+// items are kept and the whole document is a single document.
+func OdtWithMarkdown(markdown bool) ODTOptions {
+	return func(odt *ODT) {
+		odt.markdown = markdown
+	}
+}
+
+// OdtWithTables sets the format of the tables, DocxTablesMarkdown or DocxTablesRows.
+func OdtWithTables(format string) ODTOptions {
+	return func(odt *ODT) {
+		odt.tables = format
+	}
+}
+
+// NewODT creates a new odt loader with an io.ReaderAt.
+func NewODT(r io.ReaderAt, size int64, opts ...ODTOptions) ODT {
+	o := ODT{r: r, s: size}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Load reads from the io.ReaderAt and returns the documents of the text.
+func (o ODT) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, o.LoadIter(ctx))
+}
+
+// LoadIter yields the groups of paragraphs separated by the empty paragraphs, each table is a group,
+// like the docx loader does. In markdown mode the whole text is a single document.
+func (o ODT) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(schema.Document{}, err)
+			return
+		}
+		blocks, err := odf.ReadText(o.r, o.s)
+		if err != nil {
+			yield(schema.Document{}, odfError(err))
+			return
+		}
+
+		if o.markdown {
+			markdown := []markdownBlock{}
+			for _, block := range blocks {
+				if block.Table != nil {
+					if text := odfTableText(*block.Table, o.tableFormat()); text != "" {
+						markdown = append(markdown, markdownBlock{text: text})
+					}
+				} else if p, ok := odfMarkdownParagraph(*block.Paragraph); ok {
+					markdown = append(markdown, p)
+				}
+			}
+			yield(schema.Document{PageContent: joinMarkdownBlocks(markdown), Metadata: map[string]any{}}, nil)
+			return
+		}
+
+		groups := []string{}
+		group := ""
+		flush := func() {
+			if group != "" {
+				groups = append(groups, group)
+				group = ""
+			}
+		}
+		for _, block := range blocks {
+			if block.Table != nil {
+				flush()
+				if text := odfTableText(*block.Table, o.tableFormat()); text != "" {
+					groups = append(groups, text+"\n")
+				}
+				continue
+			}
+			text := strings.TrimSpace(block.Paragraph.Text)
+			if text == "" {
+				flush()
+				continue
+			}
+			group += text + "\n"
+		}
+		flush()
+
+		docs := make([]schema.Document, len(groups))
+		for i, text := range groups {
+			docs[i] = schema.Document{
+				PageContent: text,
+				Metadata: map[string]any{
+					"paragraph":       i,
+					"total_paragraph": len(groups),
+				},
+			}
+		}
+		yieldAll(ctx, docs, yield)
+	}
+}
+
+// tableFormat returns the format of the tables, markdown tables by default in markdown mode
+func (o ODT) tableFormat() string {
+	if o.tables != "" {
+		return o.tables
+	}
+	if o.markdown {
+		return DocxTablesMarkdown
+	}
+	return DocxTablesRows
+}
+
+// odfTableText returns the table in the format, the merged cells are handled like the docx tables
+func odfTableText(table odf.Table, format string) string {
+	grid, spanned := table.Grid()
+	return gridText(grid, func(r, c int) bool {
+		return c < len(spanned[r]) && spanned[r][c]
+	}, format)
+}
+
+// odfMarkdownParagraph converts the paragraph into a heading, a list item or a paragraph of text,
+// ok is false when the paragraph has no text
+func odfMarkdownParagraph(p odf.Paragraph) (block markdownBlock, ok bool) {
+	text := strings.TrimSpace(strings.ReplaceAll(p.Text, "\u00a0", " "))
+	if text == "" {
+		return markdownBlock{}, false
+	}
+	if p.Heading > 0 {
+		return markdownBlock{text: strings.Repeat("#", min(p.Heading, 6)) + " " + strings.Join(strings.Fields(text), " ")}, true
+	}
+	if p.List > 0 {
+		return markdownBlock{text: strings.Repeat("  ", p.List-1) + "- " + strings.Join(strings.Fields(text), " "), item: true}, true
+	}
+	return markdownBlock{text: text}, true
+}
+
+// odfError returns the error of an OpenDocument file failed to read
+func odfError(err error) error {
+	if errors.Is(err, odf.ErrEncrypted) {
+		return NewError(ErrEncrypted, err)
+	}
+	return NewError(ErrCorrupt, err)
+}
+
+// LoadAndSplit reads text data from the io.ReaderAt and splits it into multiple
+// documents using a text splitter.
+func (o ODT) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := o.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
@@ -2,7 +2,12 @@ package loaders
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"iter"
+	"strconv"
+	"strings"
 
 	"loader/schema"
 	"loader/textsplitter"
@@ -11,7 +16,7 @@ import (
 )
 
 /**
-限制，只能处理文本类型的pdf，不能处理图片、表格、图表等类型的pdf
+限制，图片类型的pdf页面（扫描页）需要使用 PdfWithOCR 配置 OCR 才能识别文字，不能处理图表等类型的内容
 */
 
 // PDF loads text data from an io.Reader.
@@ -19,9 +24,16 @@ type PDF struct {
 	r        io.ReaderAt
 	s        int64
 	password string
+	layout   bool
+	tables   string
+	pages    string
+	onError  func(page int, err error)
+	images   bool
+	ocr      OCRProvider
+	annots   string
 }
 
-var _ Loader = PDF{}
+var _ IterLoader = PDF{}
 
 // PDFOptions are options for the PDF loader.
 type PDFOptions func(pdf *PDF)
@@ -33,6 +45,92 @@ func PdfWithPassword(password string) PDFOptions {
 	}
 }
 
+// PdfWithLayout extracts the text in reading order from the positions of the characters instead of the
+// order they are drawn in: the columns are read one after the other, the lines are joined into paragraphs,
+// the hyphenated words are repaired, and the repeated headers and footers and the page numbers are removed.
+// The pages are read twice to find the repeated headers and footers.
+func PdfWithLayout(layout bool) PDFOptions {
+	return func(pdf *PDF) {
+		pdf.layout = layout
+	}
+}
+
+// PdfWithPages only loads the pages in the ranges, e.g. "1-5,10" loads the pages 1 to 5 and the page 10,
+// "20-" loads the pages from 20 to the end. The pages are numbered from 1, the pages after the end are ignored.
+// The ranges are checked by Load, ParsePageRanges checks them beforehand.
+func PdfWithPages(pages string) PDFOptions {
+	return func(pdf *PDF) {
+		pdf.pages = pages
+	}
+}
+
+// PdfWithPageErrorHandler sets the handler of the pages failed to read, the failed pages are then
+// skipped instead of aborting the whole load.
+func PdfWithPageErrorHandler(handler func(page int, err error)) PDFOptions {
+	return func(pdf *PDF) {
+		pdf.onError = handler
+	}
+}
+
+// PdfWithImagePlaceholders yields an empty document for each image only page, like the scanned pages
+// without a text layer, with the metadata "image_only" set to true. The image only pages are skipped otherwise,
+// they are listed in the "image_pages" metadata of the documents in both cases.
+func PdfWithImagePlaceholders(placeholders bool) PDFOptions {
+	return func(pdf *PDF) {
+		pdf.images = placeholders
+	}
+}
+
+// PdfWithOCR recognizes the text of the image only pages with the OCR provider, the documents of the
+// recognized pages have the metadata "ocr" set to true. The JPEG, JPEG 2000 and the raw gray and RGB images
+// are recognized, the pages with other images are left image only.
+func PdfWithOCR(ocr OCRProvider) PDFOptions {
+	return func(pdf *PDF) {
+		pdf.ocr = ocr
+	}
+}
+
+// The annotation modes of PdfWithAnnotations
+const (
+	// PdfAnnotationsInline appends the annotations to the text of the page
+	PdfAnnotationsInline = "inline"
+	// PdfAnnotationsSeparate returns each annotation as a document of its own
+	PdfAnnotationsSeparate = "separate"
+)
+
+// PdfWithAnnotations extracts the form fields with a value, the comments and the URI links of the pages.
+// The fields are written "name: value", the comments with their author and the text they highlight, and the
+// links with their text. With PdfAnnotationsInline they are appended to the text of the page, with
+// PdfAnnotationsSeparate each one is a document with the metadata "kind" set to "form_field", "annotation"
+// or "link", along with the "field", "subtype", "author" or "uri" metadata. An empty mode doesn't extract them.
+func PdfWithAnnotations(mode string) PDFOptions {
+	return func(pdf *PDF) {
+		pdf.annots = mode
+	}
+}
+
+// The table modes of PdfWithTables
+const (
+	// PdfTablesInline writes the tables in markdown in the text of the page
+	PdfTablesInline = "inline"
+	// PdfTablesSeparate returns each table in markdown as a document of its own
+	PdfTablesSeparate = "separate"
+)
+
+// PdfWithTables detects the tables of the pages from the alignment of the text and the cell borders and
+// converts them into markdown tables, the first row is the header. With PdfTablesInline the tables are written
+// in the text of the page, with PdfTablesSeparate each table is a document with the metadata "type" set to
+// "table" and its number in the page, the text of the page is left without it. The tables are detected with
+// the layout extraction only, it is turned on by the option. An empty mode doesn't detect the tables.
+func PdfWithTables(mode string) PDFOptions {
+	return func(pdf *PDF) {
+		pdf.tables = mode
+		if mode != "" {
+			pdf.layout = true
+		}
+	}
+}
+
 // NewPDF creates a new text loader with an io.Reader.
 func NewPDF(r io.ReaderAt, size int64, opts ...PDFOptions) PDF {
 	pdf := PDF{
@@ -55,70 +153,382 @@ func (p *PDF) getPassword() string {
 }
 
 // Load reads from the io.Reader for the PDF data and returns the documents with the data and with
-// metadata attached of the page number and total number of pages of the PDF.
-func (p PDF) Load(_ context.Context) ([]schema.Document, error) {
-	var reader *pdf.Reader
-	var err error
+// metadata attached of the page number, the total number of pages of the PDF and the extraction mode,
+// "layout" or "plain". The tables returned separately have the metadata "type" and "table" too.
+//
+// The title, author, subject, keywords, creation_date and producer fields of the PDF are added when they
+// are set, and when the PDF has bookmarks, the heading path of the page as "section_path" and its last
+// heading as "section".
+//
+// The pages with images but no fonts are image only, the scanned pages without a text layer. Their numbers are
+// listed in the "image_pages" metadata and "scanned_ratio" is the ratio of the image only pages among the loaded
+// pages, so the scanned documents can be passed to OCR.
+func (p PDF) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, p.LoadIter(ctx))
+}
 
-	if p.password != "" {
-		reader, err = pdf.NewReaderEncrypted(p.r, p.s, p.getPassword)
+// LoadIter reads the pages one by one and yields a document for each page with text, followed by
+// the documents of its tables and annotations when they are returned separately. The pages without fonts are skipped,
+// but for the placeholders of the image only pages.
+func (p PDF) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		ranges, err := ParsePageRanges(p.pages)
 		if err != nil {
-			return nil, err
+			yield(schema.Document{}, err)
+			return
 		}
-	} else {
-		reader, err = pdf.NewReader(p.r, p.s)
+		reader, err := p.open()
 		if err != nil {
-			return nil, err
+			yield(schema.Document{}, err)
+			return
+		}
+
+		numPages := reader.NumPage()
+		pages := selectPages(ranges, numPages)
+		info := documentInfo(reader)
+		outline := documentOutline(reader)
+
+		imagePages, err := imageOnlyPages(ctx, reader, pages)
+		if err != nil {
+			yield(schema.Document{}, err)
+			return
+		}
+		imageOnly := map[int]bool{}
+		for _, num := range imagePages {
+			imageOnly[num] = true
+		}
+		info["scanned_ratio"] = 0.0
+		if len(pages) > 0 {
+			info["scanned_ratio"] = float64(len(imagePages)) / float64(len(pages))
+		}
+		if len(imagePages) > 0 {
+			info["image_pages"] = imagePages
+		}
+
+		mode := "plain"
+		var margins map[string]bool
+		if p.layout {
+			mode = "layout"
+			margins, err = repeatedMargins(ctx, reader, pages)
+			if err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+		}
+
+		// fonts to be used when getting plain text from pages
+		fonts := make(map[string]*pdf.Font)
+		for _, i := range pages {
+			if err := ctx.Err(); err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+			var text string
+			var tables []string
+			var ok, recognized bool
+			switch {
+			case p.ocr != nil && imageOnly[i]:
+				text, err = recognizePage(ctx, p.r, reader, i, p.ocr)
+				ok, recognized = text != "", true
+			case p.layout:
+				text, tables, ok, err = layoutPageText(reader, i, margins, p.tables)
+			default:
+				text, ok, err = pageText(reader, i, fonts)
+			}
+			var annotations []pageAnnotation
+			if err == nil && p.annots != "" {
+				annotations, err = pageAnnotations(reader, i)
+			}
+			if err != nil && ctx.Err() != nil {
+				yield(schema.Document{}, ctx.Err())
+				return
+			}
+			if err != nil && p.onError != nil {
+				p.onError(i, err)
+				continue
+			}
+			if err != nil {
+				yield(schema.Document{}, fmt.Errorf("page %d: %w", i, err))
+				return
+			}
+			if p.annots == PdfAnnotationsInline && len(annotations) > 0 {
+				if text != "" {
+					text += "\n\n"
+				}
+				text += annotationsText(annotations)
+				ok, annotations = true, nil
+			}
+			if !ok && len(annotations) == 0 {
+				if p.images && imageOnly[i] {
+					doc := schema.Document{Metadata: pageMetadata(i, numPages, mode, info, outline)}
+					doc.Metadata["image_only"] = true
+					if !yield(doc, nil) {
+						return
+					}
+				}
+				continue
+			}
+
+			// add the document to the doc list, the page is only skipped when all its text is in the tables
+			// or when it only has annotations
+			if ok && (text != "" || len(tables) == 0) {
+				doc := schema.Document{
+					PageContent: text + "\n",
+					Metadata:    pageMetadata(i, numPages, mode, info, outline),
+				}
+				if recognized {
+					doc.Metadata["ocr"] = true
+				}
+				if !yield(doc, nil) {
+					return
+				}
+			}
+			for n, table := range tables {
+				doc := schema.Document{
+					PageContent: table + "\n",
+					Metadata:    pageMetadata(i, numPages, mode, info, outline),
+				}
+				doc.Metadata["type"] = "table"
+				doc.Metadata["table"] = n + 1
+				if !yield(doc, nil) {
+					return
+				}
+			}
+			for _, annotation := range annotations {
+				doc := schema.Document{
+					PageContent: annotation.text + "\n",
+					Metadata:    pageMetadata(i, numPages, mode, info, outline),
+				}
+				doc.Metadata["kind"] = annotation.kind
+				for key, value := range annotation.metadata {
+					doc.Metadata[key] = value
+				}
+				if !yield(doc, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// pageMetadata returns the metadata of the documents of the page
+func pageMetadata(num int, numPages int, mode string, info map[string]any, outline []outlineEntry) map[string]any {
+	metadata := map[string]any{
+		"page":        num,
+		"total_pages": numPages,
+		"extraction":  mode,
+	}
+	for key, value := range info {
+		metadata[key] = value
+	}
+	if path := sectionOf(outline, num); len(path) > 0 {
+		metadata["section_path"] = path
+		metadata["section"] = path[len(path)-1]
+	}
+	return metadata
+}
+
+// open opens the pdf reader, p is a copy so the password is kept for the next call.
+func (p PDF) open() (reader *pdf.Reader, err error) {
+	// the pdf reader panics on the malformed objects
+	defer func() {
+		if r := recover(); r != nil {
+			reader = nil
+			err = NewError(ErrCorrupt, fmt.Errorf("%v", r))
 		}
+	}()
+
+	withPassword := p.password != ""
+	if withPassword {
+		reader, err = pdf.NewReaderEncrypted(p.r, p.s, p.getPassword)
+	} else {
+		reader, err = pdf.NewReader(p.r, p.s)
 	}
+	if err != nil {
+		return nil, pdfError(err, withPassword)
+	}
+	return reader, nil
+}
+
+// pageText returns the plain text of the page, ok is false when there are no fonts in the page.
+// The fonts of the page are added to the fonts map shared by the pages.
+func pageText(reader *pdf.Reader, num int, fonts map[string]*pdf.Font) (text string, ok bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewError(ErrCorrupt, fmt.Errorf("%v", r))
+		}
+	}()
 
-	numPages := reader.NumPage()
+	p := reader.Page(num)
+	if len(p.Fonts()) == 0 {
+		// no fonts in page, skip
+		return "", false, nil
+	}
+	// add fonts to map
+	for _, name := range p.Fonts() {
+		// only add the font if we don't already have it
+		if _, ok := fonts[name]; !ok {
+			f := p.Font(name)
+			fonts[name] = &f
+		}
+	}
+	text, err = p.GetPlainText(fonts)
+	if err != nil {
+		return "", false, NewError(ErrCorrupt, err)
+	}
+	return text, true, nil
+}
 
-	docs := []schema.Document{}
+// layoutPageText returns the text of the page in reading order, ok is false when there are no fonts in the page.
+// The margin rows whose keys are in margins are removed. The tables are detected when the tables mode is set,
+// they are returned apart from the text in the PdfTablesSeparate mode.
+func layoutPageText(reader *pdf.Reader, num int, margins map[string]bool, tables string) (text string, separate []string, ok bool, err error) {
+	layout, ok, err := pageLayoutOf(reader, num)
+	if err != nil || !ok {
+		return "", nil, false, err
+	}
+	paragraphs := layout.paragraphs(margins, tables != "")
+	if tables != PdfTablesSeparate {
+		return joinParagraphs(paragraphs), nil, true, nil
+	}
 
-	// fonts to be used when getting plain text from pages
-	fonts := make(map[string]*pdf.Font)
-	for i := 1; i < numPages+1; i++ {
-		p := reader.Page(i)
-		if len(p.Fonts()) == 0 {
-			// no fonts in page, skip
+	textParagraphs := []layoutParagraph{}
+	for _, paragraph := range paragraphs {
+		if paragraph.table {
+			separate = append(separate, paragraph.text)
 			continue
 		}
-		// add fonts to map
-		for _, name := range p.Fonts() {
-			// only add the font if we don't already have it
-			if _, ok := fonts[name]; !ok {
-				f := p.Font(name)
-				fonts[name] = &f
+		textParagraphs = append(textParagraphs, paragraph)
+	}
+	return joinParagraphs(textParagraphs), separate, true, nil
+}
+
+// pageLayoutOf returns the layout of the characters of the page, ok is false when there are no fonts in the page
+func pageLayoutOf(reader *pdf.Reader, num int) (layout pageLayout, ok bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewError(ErrCorrupt, fmt.Errorf("%v", r))
+		}
+	}()
+
+	p := reader.Page(num)
+	if len(p.Fonts()) == 0 {
+		return pageLayout{}, false, nil
+	}
+	content := p.Content()
+	return newPageLayout(content.Text, content.Rect, mediaBox(p)), true, nil
+}
+
+// mediaBox returns the media box of the page, inherited from the parent pages when it is not set on the page.
+// The box is empty when it is not found.
+func mediaBox(p pdf.Page) pdf.Rect {
+	// the depth is limited in case of a loop in the malformed page tree
+	for v, depth := p.V, 0; !v.IsNull() && depth < 32; v, depth = v.Key("Parent"), depth+1 {
+		if box := v.Key("MediaBox"); box.Len() == 4 {
+			return pdf.Rect{
+				Min: pdf.Point{X: box.Index(0).Float64(), Y: box.Index(1).Float64()},
+				Max: pdf.Point{X: box.Index(2).Float64(), Y: box.Index(3).Float64()},
 			}
 		}
-		text, err := p.GetPlainText(fonts)
-		if err != nil {
+	}
+	return pdf.Rect{}
+}
+
+// repeatedMargins returns the keys of the headers and footers repeated on at least half of the pages.
+// The pages failed to read are ignored here, their errors are returned when their text is read.
+func repeatedMargins(ctx context.Context, reader *pdf.Reader, pages []int) (map[string]bool, error) {
+	counts := map[string]int{}
+	for _, i := range pages {
+		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
+		layout, ok, err := pageLayoutOf(reader, i)
+		if err != nil || !ok {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, key := range layout.marginKeys() {
+			if !seen[key] {
+				seen[key] = true
+				counts[key]++
+			}
+		}
+	}
+
+	repeated := map[string]bool{}
+	for key, count := range counts {
+		if count >= 2 && count*2 >= len(pages) {
+			repeated[key] = true
+		}
+	}
+	return repeated, nil
+}
+
+// PageRange is a range of pages from From to To, To is zero when the range goes to the last page
+type PageRange struct {
+	From int
+	To   int
+}
+
+// ParsePageRanges parses the comma separated page ranges of PdfWithPages, e.g. "1-5,10" or "20-".
+// All the pages are selected when s is empty.
+func ParsePageRanges(s string) ([]PageRange, error) {
+	ranges := []PageRange{}
+	if strings.TrimSpace(s) == "" {
+		return ranges, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil || first < 1 {
+			return nil, fmt.Errorf("invalid page range %q", part)
+		}
+		last := first
+		if isRange {
+			last = 0
+			if to = strings.TrimSpace(to); to != "" {
+				last, err = strconv.Atoi(to)
+				if err != nil || last < first {
+					return nil, fmt.Errorf("invalid page range %q", part)
+				}
+			}
+		}
+		ranges = append(ranges, PageRange{From: first, To: last})
+	}
+	return ranges, nil
+}
+
+// selectPages returns the numbers of the pages in the ranges in ascending order, all the pages when there are no ranges
+func selectPages(ranges []PageRange, numPages int) []int {
+	selected := make([]bool, max(numPages, 0)+1)
+	for _, r := range ranges {
+		to := r.To
+		if to == 0 || to > numPages {
+			to = numPages
+		}
+		for i := r.From; i <= to; i++ {
+			selected[i] = true
+		}
+	}
+	pages := []int{}
+	for i := 1; i <= numPages; i++ {
+		if selected[i] || len(ranges) == 0 {
+			pages = append(pages, i)
+		}
+	}
+	return pages
+}
 
-		// content := []byte{}
-		// rows, _ := p.GetTextByRow()
-		// for _, row := range rows {
-		// 	for _, word := range row.Content {
-		// 		content = append(content, word.S...)
-		// 	}
-		// }
-		// x1 := string(content)
-		// println(x1)
-
-		// add the document to the doc list
-		text += "\n"
-		docs = append(docs, schema.Document{
-			PageContent: text,
-			Metadata: map[string]any{
-				"page":        i,
-				"total_pages": numPages,
-			},
-		})
-	}
-
-	return docs, nil
+// pdfError returns the error of the pdf failed to open
+func pdfError(err error, withPassword bool) error {
+	if errors.Is(err, pdf.ErrInvalidPassword) {
+		if withPassword {
+			return NewError(ErrBadPassword, err)
+		}
+		return NewError(ErrEncrypted, err)
+	}
+	return NewError(ErrCorrupt, err)
 }
 
 // LoadAndSplit reads pdf data from the io.Reader and splits it into multiple
@@ -0,0 +1,219 @@
+package loaders
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// The kinds of the page annotations, the "kind" metadata of their documents
+const (
+	annotationField   = "form_field"
+	annotationComment = "annotation"
+	annotationLink    = "link"
+)
+
+// markupAnnotations are the annotations marking the text of the page, the marked text is extracted
+var markupAnnotations = map[string]bool{"Highlight": true, "Underline": true, "StrikeOut": true, "Squiggly": true}
+
+// pageAnnotation is a form field, a comment or a link of a page
+type pageAnnotation struct {
+	kind     string
+	text     string
+	metadata map[string]any
+}
+
+// pageAnnotations returns the form fields with a value, the comments and the URI links of the page
+func pageAnnotations(reader *pdf.Reader, num int) (annotations []pageAnnotation, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewError(ErrCorrupt, fmt.Errorf("%v", r))
+		}
+	}()
+
+	p := reader.Page(num)
+	annots := p.V.Key("Annots")
+	var glyphs []pdf.Text
+	markedText := func(boxes []pdf.Rect) string {
+		if glyphs == nil {
+			glyphs = placeGlyphs(p.Content().Text)
+		}
+		return textIn(glyphs, boxes)
+	}
+
+	fields := map[string]bool{}
+	for i := 0; i < annots.Len(); i++ {
+		annot := annots.Index(i)
+		subtype := annot.Key("Subtype").Name()
+		switch {
+		case subtype == "Widget":
+			name, value := formField(annot)
+			if name == "" || value == "" || fields[name] {
+				continue
+			}
+			// the widgets of the radio buttons share the field
+			fields[name] = true
+			annotations = append(annotations, pageAnnotation{
+				kind:     annotationField,
+				text:     name + ": " + value,
+				metadata: map[string]any{"field": name},
+			})
+
+		case subtype == "Link":
+			action := annot.Key("A")
+			uri := strings.TrimSpace(action.Key("URI").RawString())
+			if action.Key("S").Name() != "URI" || uri == "" {
+				continue
+			}
+			text := uri
+			if label := markedText([]pdf.Rect{annotationRect(annot)}); label != "" && label != uri {
+				text = label + " (" + uri + ")"
+			}
+			annotations = append(annotations, pageAnnotation{
+				kind:     annotationLink,
+				text:     text,
+				metadata: map[string]any{"uri": uri},
+			})
+
+		case subtype != "Popup":
+			parts := []string{}
+			if markupAnnotations[subtype] {
+				if marked := markedText(quadBoxes(annot)); marked != "" {
+					parts = append(parts, "\""+marked+"\"")
+				}
+			}
+			contents := strings.TrimSpace(annot.Key("Contents").Text())
+			author := strings.TrimSpace(annot.Key("T").Text())
+			if contents != "" && author != "" {
+				contents = author + ": " + contents
+			}
+			if contents != "" {
+				parts = append(parts, contents)
+			}
+			if len(parts) == 0 {
+				continue
+			}
+			metadata := map[string]any{"subtype": subtype}
+			if author != "" {
+				metadata["author"] = author
+			}
+			annotations = append(annotations, pageAnnotation{
+				kind:     annotationComment,
+				text:     strings.Join(parts, " "),
+				metadata: metadata,
+			})
+		}
+	}
+	return annotations, nil
+}
+
+// formField returns the full name and the value of the form field of the widget,
+// the name and the value are inherited from the parent fields.
+func formField(widget pdf.Value) (name string, value string) {
+	names := []string{}
+	var v pdf.Value
+	for field, depth := widget, 0; field.Kind() == pdf.Dict && depth < maxOutlineDepth; field, depth = field.Key("Parent"), depth+1 {
+		if t := strings.TrimSpace(field.Key("T").Text()); t != "" {
+			names = append([]string{t}, names...)
+		}
+		if v.IsNull() {
+			v = field.Key("V")
+		}
+	}
+
+	switch v.Kind() {
+	case pdf.String:
+		value = v.Text()
+	case pdf.Name:
+		// the state of the check boxes and radio buttons
+		if v.Name() != "Off" {
+			value = v.Name()
+		}
+	case pdf.Array:
+		values := []string{}
+		for i := 0; i < v.Len(); i++ {
+			if s := strings.TrimSpace(v.Index(i).Text()); s != "" {
+				values = append(values, s)
+			}
+		}
+		value = strings.Join(values, ", ")
+	case pdf.Integer, pdf.Real:
+		value = fmt.Sprint(v.Float64())
+	}
+	return strings.Join(names, "."), strings.TrimSpace(value)
+}
+
+// annotationRect returns the rectangle of the annotation
+func annotationRect(annot pdf.Value) pdf.Rect {
+	rect := annot.Key("Rect")
+	if rect.Len() != 4 {
+		return pdf.Rect{}
+	}
+	return pdf.Rect{
+		Min: pdf.Point{X: math.Min(rect.Index(0).Float64(), rect.Index(2).Float64()), Y: math.Min(rect.Index(1).Float64(), rect.Index(3).Float64())},
+		Max: pdf.Point{X: math.Max(rect.Index(0).Float64(), rect.Index(2).Float64()), Y: math.Max(rect.Index(1).Float64(), rect.Index(3).Float64())},
+	}
+}
+
+// quadBoxes returns the bounding boxes of the quadrilaterals of the markup annotation,
+// the rectangle of the annotation when there are none.
+func quadBoxes(annot pdf.Value) []pdf.Rect {
+	quads := annot.Key("QuadPoints")
+	boxes := []pdf.Rect{}
+	for i := 0; i+8 <= quads.Len(); i += 8 {
+		box := pdf.Rect{
+			Min: pdf.Point{X: math.Inf(1), Y: math.Inf(1)},
+			Max: pdf.Point{X: math.Inf(-1), Y: math.Inf(-1)},
+		}
+		for j := i; j < i+8; j += 2 {
+			x, y := quads.Index(j).Float64(), quads.Index(j+1).Float64()
+			box.Min.X, box.Max.X = math.Min(box.Min.X, x), math.Max(box.Max.X, x)
+			box.Min.Y, box.Max.Y = math.Min(box.Min.Y, y), math.Max(box.Max.Y, y)
+		}
+		boxes = append(boxes, box)
+	}
+	if len(boxes) == 0 {
+		boxes = append(boxes, annotationRect(annot))
+	}
+	return boxes
+}
+
+// textIn returns the text of the characters whose centers are in one of the boxes, in reading order
+func textIn(glyphs []pdf.Text, boxes []pdf.Rect) string {
+	selected := []pdf.Text{}
+	for _, g := range glyphs {
+		x, y := g.X+g.W/2, g.Y+g.FontSize/3
+		for _, box := range boxes {
+			if x >= box.Min.X-1 && x <= box.Max.X+1 && y >= box.Min.Y-1 && y <= box.Max.Y+1 {
+				selected = append(selected, g)
+				break
+			}
+		}
+	}
+	layout := newPageLayout(selected, nil, pdf.Rect{})
+	rows := make([]string, 0, layout.rows)
+	for row := 0; row < layout.rows; row++ {
+		rows = append(rows, layout.rowText(row))
+	}
+	return strings.Join(strings.Fields(strings.Join(rows, " ")), " ")
+}
+
+// annotationsText returns the annotations as lines of text, grouped by kind
+func annotationsText(annotations []pageAnnotation) string {
+	titles := map[string]string{annotationField: "Form fields:", annotationComment: "Annotations:", annotationLink: "Links:"}
+	blocks := []string{}
+	for _, kind := range []string{annotationField, annotationComment, annotationLink} {
+		lines := []string{}
+		for _, annotation := range annotations {
+			if annotation.kind == kind {
+				lines = append(lines, "- "+annotation.text)
+			}
+		}
+		if len(lines) > 0 {
+			blocks = append(blocks, titles[kind]+"\n"+strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
@@ -0,0 +1,74 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// annotatedPDF returns a PDF of a page with form fields, a note, a highlight, a link and a popup
+func annotatedPDF() []byte {
+	return buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [6 0 R 8 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R "+
+			"/Annots [7 0 R 8 0 R 9 0 R 10 0 R 11 0 R 12 0 R] >>",
+		pdfText("Pay the invoice now"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /T (contract) /Kids [7 0 R] >>",
+		"<< /Type /Annot /Subtype /Widget /Parent 6 0 R /T (buyer) /FT /Tx /V (Acme Inc.) /Rect [72 600 300 620] >>",
+		"<< /Type /Annot /Subtype /Widget /T (agree) /FT /Btn /V /Yes /Rect [72 560 90 578] >>",
+		"<< /Type /Annot /Subtype /Text /T (Bob) /Contents (Check the amount) /Rect [400 700 420 720] /Popup 12 0 R >>",
+		"<< /Type /Annot /Subtype /Highlight /QuadPoints [60 715 500 715 60 695 500 695] /Rect [60 695 500 715] >>",
+		"<< /Type /Annot /Subtype /Link /A << /S /URI /URI (https://example.com/terms) >> /Rect [72 500 200 520] >>",
+		"<< /Type /Annot /Subtype /Popup /Parent 9 0 R /Contents (Check the amount) /Rect [400 600 500 700] >>",
+	)
+}
+
+func TestPDFAnnotations(t *testing.T) {
+	t.Parallel()
+
+	data := annotatedPDF()
+	docs, err := NewPDF(bytes.NewReader(data), int64(len(data)), PdfWithAnnotations(PdfAnnotationsSeparate)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 6)
+
+	assert.Equal(t, "Pay the invoice now\n", docs[0].PageContent)
+	assert.NotContains(t, docs[0].Metadata, "kind")
+
+	expected := []struct {
+		content string
+		kind    string
+		key     string
+		value   any
+	}{
+		{"contract.buyer: Acme Inc.\n", "form_field", "field", "contract.buyer"},
+		{"agree: Yes\n", "form_field", "field", "agree"},
+		{"Bob: Check the amount\n", "annotation", "author", "Bob"},
+		{"\"Pay the invoice now\"\n", "annotation", "subtype", "Highlight"},
+		{"https://example.com/terms\n", "link", "uri", "https://example.com/terms"},
+	}
+	for i, e := range expected {
+		doc := docs[i+1]
+		assert.Equal(t, e.content, doc.PageContent)
+		assert.Equal(t, e.kind, doc.Metadata["kind"])
+		assert.Equal(t, e.value, doc.Metadata[e.key])
+		assert.Equal(t, 1, doc.Metadata["page"])
+	}
+
+	docs, err = NewPDF(bytes.NewReader(data), int64(len(data)), PdfWithAnnotations(PdfAnnotationsInline)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Pay the invoice now\n\n"+
+		"Form fields:\n- contract.buyer: Acme Inc.\n- agree: Yes\n\n"+
+		"Annotations:\n- Bob: Check the amount\n- \"Pay the invoice now\"\n\n"+
+		"Links:\n- https://example.com/terms\n", docs[0].PageContent)
+
+	docs, err = NewPDF(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Pay the invoice now\n", docs[0].PageContent)
+}
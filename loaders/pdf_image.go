@@ -0,0 +1,238 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// maxFormDepth limits the depth of the nested form XObjects, malformed files may have loops
+const maxFormDepth = 8
+
+// pageImages returns the image XObjects of the page resources, the images of its form XObjects included
+func pageImages(p pdf.Page) []pdf.Value {
+	images := []pdf.Value{}
+	var walk func(resources pdf.Value, depth int)
+	walk = func(resources pdf.Value, depth int) {
+		xobjects := resources.Key("XObject")
+		for _, name := range xobjects.Keys() {
+			xobject := xobjects.Key(name)
+			switch xobject.Key("Subtype").Name() {
+			case "Image":
+				images = append(images, xobject)
+			case "Form":
+				if depth < maxFormDepth {
+					walk(xobject.Key("Resources"), depth+1)
+				}
+			}
+		}
+	}
+	walk(p.Resources(), 0)
+	return images
+}
+
+// isImageOnly reports whether the page has images but no fonts, like the scanned pages without a text layer.
+// The pages failed to read are not image only, their errors are returned when their text is read.
+func isImageOnly(reader *pdf.Reader, num int) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+
+	p := reader.Page(num)
+	return len(p.Fonts()) == 0 && len(pageImages(p)) > 0
+}
+
+// imageOnlyPages returns the image only pages among the pages
+func imageOnlyPages(ctx context.Context, reader *pdf.Reader, pages []int) ([]int, error) {
+	images := []int{}
+	for _, num := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if isImageOnly(reader, num) {
+			images = append(images, num)
+		}
+	}
+	return images, nil
+}
+
+// maxImagePixels limits the size of the images to 64 megapixels, larger images are not recognized.
+// It also bounds the bytes of the stored images.
+const maxImagePixels = 64 << 20
+
+// recognizePage returns the text recognized in the images of the page, r is the data of the PDF
+func recognizePage(ctx context.Context, r io.ReaderAt, reader *pdf.Reader, num int, ocr OCRProvider) (string, error) {
+	files, err := pageImageFiles(r, reader, num)
+	if err != nil {
+		return "", err
+	}
+	return recognizeImages(ctx, ocr, files)
+}
+
+// pageImageFiles returns the images of the page that can be recognized, r is the data of the PDF.
+// The JPEG and JPEG 2000 images are returned as they are stored, the raw images are converted to PNG,
+// the other encodings are not supported and left out.
+func pageImageFiles(r io.ReaderAt, reader *pdf.Reader, num int) (files []imageFile, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewError(ErrCorrupt, fmt.Errorf("%v", r))
+		}
+	}()
+
+	encrypted := !reader.Trailer().Key("Encrypt").IsNull()
+	for i, xobject := range pageImages(reader.Page(num)) {
+		name := fmt.Sprintf("page %d image %d", num, i+1)
+		data, mime, ok := imageData(r, encrypted, xobject)
+		if ok {
+			files = append(files, imageFile{name: name, mime: mime, data: data})
+		}
+	}
+	return files, nil
+}
+
+// imageData returns the data of the image XObject and its mime type, ok is false when it is not supported
+func imageData(r io.ReaderAt, encrypted bool, xobject pdf.Value) (data []byte, mime string, ok bool) {
+	// the pdf reader panics on the unsupported filters and predictors
+	defer func() {
+		if r := recover(); r != nil {
+			data, mime, ok = nil, "", false
+		}
+	}()
+
+	filters := []string{}
+	switch filter := xobject.Key("Filter"); filter.Kind() {
+	case pdf.Name:
+		filters = append(filters, filter.Name())
+	case pdf.Array:
+		for i := 0; i < filter.Len(); i++ {
+			filters = append(filters, filter.Index(i).Name())
+		}
+	}
+
+	switch {
+	case len(filters) == 1 && (filters[0] == "DCTDecode" || filters[0] == "JPXDecode"):
+		// the stored data is the image file, it can't be decrypted without the pdf reader
+		if encrypted {
+			return nil, "", false
+		}
+		data, ok := streamData(r, xobject)
+		if !ok {
+			return nil, "", false
+		}
+		// the data read at the offset must be the image file
+		switch {
+		case filters[0] == "DCTDecode" && bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+			return data, "image/jpeg", true
+		case filters[0] == "JPXDecode" && (bytes.HasPrefix(data, jp2Signature) || bytes.HasPrefix(data, jpcSignature)):
+			return data, "image/jp2", true
+		}
+		return nil, "", false
+	case len(filters) == 0 || (len(filters) == 1 && filters[0] == "FlateDecode"):
+		img, ok := rawImage(xobject)
+		if !ok {
+			return nil, "", false
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", false
+		}
+		return buf.Bytes(), "image/png", true
+	}
+	return nil, "", false
+}
+
+// The signatures of the JPEG 2000 files and codestreams
+var (
+	jp2Signature = []byte("\x00\x00\x00\x0CjP  \r\n\x87\n")
+	jpcSignature = []byte{0xFF, 0x4F, 0xFF, 0x51}
+)
+
+// streamData returns the stored data of the stream without decoding it. The pdf reader has no reader
+// of the stored data and its stream reader panics on the image filters, so the offset of the data is
+// read from the end of the string of the stream, "<<...>>@offset".
+func streamData(r io.ReaderAt, stream pdf.Value) ([]byte, bool) {
+	s := stream.String()
+	offset, err := strconv.ParseInt(s[strings.LastIndex(s, "@")+1:], 10, 64)
+	length := stream.Key("Length").Int64()
+	if err != nil || offset < 0 || length <= 0 || length > maxImagePixels {
+		return nil, false
+	}
+	data := make([]byte, length)
+	if _, err := r.ReadAt(data, offset); err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// rawImage decodes the samples of an image in gray or RGB with 1 or 8 bits per component
+func rawImage(xobject pdf.Value) (image.Image, bool) {
+	width := int(xobject.Key("Width").Int64())
+	height := int(xobject.Key("Height").Int64())
+	bits := int(xobject.Key("BitsPerComponent").Int64())
+	if xobject.Key("ImageMask").Bool() {
+		bits = 1
+	}
+	if width <= 0 || height <= 0 || width > maxImagePixels/height {
+		return nil, false
+	}
+
+	components := 0
+	space := xobject.Key("ColorSpace")
+	switch name := space.Name(); {
+	case xobject.Key("ImageMask").Bool(), name == "DeviceGray", name == "CalGray":
+		components = 1
+	case name == "DeviceRGB", name == "CalRGB":
+		components = 3
+	case space.Kind() == pdf.Array && space.Index(0).Name() == "ICCBased":
+		components = int(space.Index(1).Key("N").Int64())
+	case space.Kind() == pdf.Array && space.Index(0).Name() == "CalRGB":
+		components = 3
+	case space.Kind() == pdf.Array && space.Index(0).Name() == "CalGray":
+		components = 1
+	}
+	if (components != 1 && components != 3) || (bits != 8 && (bits != 1 || components != 1)) {
+		return nil, false
+	}
+
+	stride := (width*components*bits + 7) / 8
+	rd := xobject.Reader()
+	defer rd.Close()
+	// the samples are read as they are decoded, a short stream is not allocated the size of the image
+	samples, err := io.ReadAll(io.LimitReader(rd, int64(stride*height)))
+	if err != nil || len(samples) < stride*height {
+		return nil, false
+	}
+
+	if components == 3 {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			row := samples[y*stride:]
+			for x := 0; x < width; x++ {
+				img.Set(x, y, color.RGBA{row[x*3], row[x*3+1], row[x*3+2], 0xff})
+			}
+		}
+		return img, true
+	}
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		row := samples[y*stride:]
+		for x := 0; x < width; x++ {
+			if bits == 8 {
+				img.SetGray(x, y, color.Gray{row[x]})
+			} else if row[x/8]&(0x80>>(x%8)) != 0 {
+				img.SetGray(x, y, color.Gray{0xff})
+			}
+		}
+	}
+	return img, true
+}
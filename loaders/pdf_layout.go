@@ -0,0 +1,613 @@
+package loaders
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// The layout extraction rebuilds the reading order of a page from the positions of its characters:
+// the characters are grouped into rows, the rows are cut into segments at wide gaps, the column
+// gutters are found from the segments, and the lines of each column are joined into paragraphs.
+// The repeated headers and footers, and the page numbers, are removed from the page margins.
+
+// marginRatio is the part of the page height at the top and the bottom where headers and footers are looked for
+const marginRatio = 0.1
+
+// maxLayoutWidth is the widest span of text where the gutters are looked for, the coordinates
+// come from the content of the page and may be far out of the page
+const maxLayoutWidth = 10000
+
+// pageNumberRegexp matches the page number lines like "12", "- 12 -", "Page 3 of 10" or "iv"
+var pageNumberRegexp = regexp.MustCompile(`(?i)^(page\s*)?[-–—(\[]?\s*(\d{1,4}|[ivxlcdm]{1,6})\s*[-–—)\]]?(\s*(of|/)\s*\d{1,4})?$`)
+
+// digitsRegexp matches the numbers replaced in the header and footer keys, so "Page 1" and "Page 2" are the same
+var digitsRegexp = regexp.MustCompile(`\d+`)
+
+// layoutSegment is a run of characters on a row, separated from the other segments of the row by a wide gap
+type layoutSegment struct {
+	row    int
+	x0, x1 float64
+	y      float64
+	size   float64
+	text   string
+}
+
+// pageLayout is the text segments of a page in rows from top to bottom
+type pageLayout struct {
+	segments []layoutSegment
+	rows     int
+	// top and bottom are the vertical bounds of the page
+	top, bottom float64
+}
+
+// newPageLayout groups the characters of the page into rows and segments, box is the media box of the page,
+// the bounds of the text are used when it is empty. The segments are also cut at the vertical rules of the
+// rectangles, like the borders of the table cells.
+func newPageLayout(texts []pdf.Text, rects []pdf.Rect, box pdf.Rect) pageLayout {
+	glyphs := placeGlyphs(texts)
+	layout := pageLayout{top: box.Max.Y, bottom: box.Min.Y}
+	if len(glyphs) == 0 {
+		return layout
+	}
+	if layout.top <= layout.bottom {
+		layout.top, layout.bottom = math.Inf(-1), math.Inf(1)
+		for _, g := range glyphs {
+			layout.top = math.Max(layout.top, g.Y+g.FontSize)
+			layout.bottom = math.Min(layout.bottom, g.Y)
+		}
+	}
+
+	// rows from top to bottom, the characters of a row are within half of the font size from its first character
+	sort.SliceStable(glyphs, func(i, j int) bool { return glyphs[i].Y > glyphs[j].Y })
+	rows := [][]pdf.Text{}
+	for _, g := range glyphs {
+		if n := len(rows); n > 0 {
+			first := rows[n-1][0]
+			if first.Y-g.Y <= 0.5*math.Max(first.FontSize, g.FontSize) {
+				rows[n-1] = append(rows[n-1], g)
+				continue
+			}
+		}
+		rows = append(rows, []pdf.Text{g})
+	}
+
+	rules := []pdf.Rect{}
+	for _, rect := range rects {
+		if math.Abs(rect.Max.X-rect.Min.X) <= 2 && math.Abs(rect.Max.Y-rect.Min.Y) >= 5 {
+			rules = append(rules, rect)
+		}
+	}
+
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].X < row[j].X })
+		segments := rowSegments(row, rules, layout.rows)
+		if len(segments) > 0 {
+			layout.segments = append(layout.segments, segments...)
+			layout.rows++
+		}
+	}
+	return layout
+}
+
+// placeGlyphs returns the visible characters with their widths. The characters of the fonts without widths
+// are all drawn at the start of the text, they are moved by their estimated widths.
+func placeGlyphs(texts []pdf.Text) []pdf.Text {
+	glyphs := make([]pdf.Text, 0, len(texts))
+	var origin pdf.Point
+	var advance float64
+	for _, t := range texts {
+		if t.S == "" || t.S == "\n" || t.S == "\r" {
+			continue
+		}
+		t.FontSize = math.Abs(t.FontSize)
+		if t.FontSize == 0 {
+			t.FontSize = 10
+		}
+		if t.W <= 0 {
+			if len(glyphs) > 0 && t.X == origin.X && t.Y == origin.Y {
+				t.X += advance
+			} else {
+				origin, advance = pdf.Point{X: t.X, Y: t.Y}, 0
+			}
+			t.W = estimateWidth(t.S, t.FontSize)
+			advance += t.W
+		} else {
+			origin = pdf.Point{X: math.NaN(), Y: math.NaN()}
+		}
+		glyphs = append(glyphs, t)
+	}
+	return glyphs
+}
+
+// rowSegments cuts the characters of a row sorted by x into segments at the gaps wider than the font size
+// and at the vertical rules, spaces are added between the words from the explicit space characters and
+// the gaps between characters.
+func rowSegments(row []pdf.Text, rules []pdf.Rect, rowIndex int) []layoutSegment {
+	segments := []layoutSegment{}
+	var text strings.Builder
+	var seg layoutSegment
+	var prev *pdf.Text
+
+	flush := func() {
+		seg.text = strings.TrimSpace(text.String())
+		if seg.text != "" {
+			segments = append(segments, seg)
+		}
+		text.Reset()
+	}
+
+	for i := range row {
+		g := &row[i]
+		if prev != nil {
+			// the characters drawn twice to fake bold text
+			if g.S == prev.S && math.Abs(g.X-prev.X) < 0.1*g.FontSize && math.Abs(g.Y-prev.Y) < 0.1*g.FontSize {
+				continue
+			}
+
+			gap := g.X - (prev.X + prev.W)
+			size := math.Max(prev.FontSize, g.FontSize)
+			switch {
+			case gap > 1.5*size || (gap > 0.1*size && crossesRule(rules, prev.X+prev.W, g.X, g.Y)):
+				flush()
+				prev = nil
+			case gap > wordGap(prev.S, g.S, size) && !strings.HasSuffix(text.String(), " "):
+				text.WriteString(" ")
+			}
+		}
+
+		if prev == nil {
+			seg = layoutSegment{row: rowIndex, x0: g.X, y: g.Y, size: g.FontSize}
+		}
+		if strings.TrimSpace(g.S) == "" {
+			if !strings.HasSuffix(text.String(), " ") {
+				text.WriteString(" ")
+			}
+		} else {
+			text.WriteString(g.S)
+		}
+		seg.x1 = math.Max(seg.x1, g.X+math.Max(g.W, 0))
+		seg.size = math.Max(seg.size, g.FontSize)
+		prev = g
+	}
+	flush()
+	return segments
+}
+
+// crossesRule reports whether a vertical rule is between x0 and x1 at the height y
+func crossesRule(rules []pdf.Rect, x0, x1, y float64) bool {
+	for _, rule := range rules {
+		minX, maxX := math.Min(rule.Min.X, rule.Max.X), math.Max(rule.Min.X, rule.Max.X)
+		minY, maxY := math.Min(rule.Min.Y, rule.Max.Y), math.Max(rule.Min.Y, rule.Max.Y)
+		if minX >= x0 && maxX <= x1 && y >= minY && y <= maxY {
+			return true
+		}
+	}
+	return false
+}
+
+// estimateWidth returns the approximate width of a character of the font size
+func estimateWidth(s string, size float64) float64 {
+	r, _ := utf8.DecodeRuneInString(s)
+	switch {
+	case isCJK(r):
+		return size
+	case unicode.IsSpace(r):
+		return 0.25 * size
+	}
+	return 0.5 * size
+}
+
+// wordGap is the gap between two characters above which they are separated by a space,
+// the CJK characters are not separated by spaces.
+func wordGap(prev, next string, size float64) float64 {
+	r1, _ := utf8.DecodeLastRuneInString(prev)
+	r2, _ := utf8.DecodeRuneInString(next)
+	if isCJK(r1) && isCJK(r2) {
+		return size
+	}
+	return 0.2 * size
+}
+
+// isCJK reports whether the rune is a Chinese, Japanese or Korean character that is written without spaces
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xffef)
+}
+
+// rowText returns the text of the row, the segments joined by spaces
+func (l pageLayout) rowText(row int) string {
+	texts := []string{}
+	for _, seg := range l.segments {
+		if seg.row == row {
+			texts = append(texts, seg.text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// marginRows returns the rows that may be headers or footers: the first and last two rows within the page margins
+func (l pageLayout) marginRows() []int {
+	height := l.top - l.bottom
+	rows := []int{}
+	for _, seg := range l.segments {
+		inTop := seg.row < 2 && seg.y >= l.top-marginRatio*height
+		inBottom := seg.row >= l.rows-2 && seg.y <= l.bottom+marginRatio*height
+		if (inTop || inBottom) && (len(rows) == 0 || rows[len(rows)-1] != seg.row) {
+			rows = append(rows, seg.row)
+		}
+	}
+	return rows
+}
+
+// marginKeys returns the keys of the header and footer candidates of the page
+func (l pageLayout) marginKeys() []string {
+	keys := []string{}
+	for _, row := range l.marginRows() {
+		keys = append(keys, marginKey(l.rowText(row)))
+	}
+	return keys
+}
+
+// marginKey normalizes the header or footer text, the numbers are replaced so the page numbers don't matter
+func marginKey(text string) string {
+	text = digitsRegexp.ReplaceAllString(strings.ToLower(text), "#")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// layoutParagraph is a paragraph of the page, or a table in markdown
+type layoutParagraph struct {
+	text  string
+	table bool
+}
+
+// joinParagraphs joins the paragraphs with blank lines
+func joinParagraphs(paragraphs []layoutParagraph) string {
+	texts := make([]string, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		texts = append(texts, paragraph.text)
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// paragraphs returns the paragraphs of the page in reading order, the tables are detected when tables is true.
+// The margin rows that are page numbers or whose keys are in repeated are removed.
+func (l pageLayout) paragraphs(repeated map[string]bool, tables bool) []layoutParagraph {
+	removed := map[int]bool{}
+	for _, row := range l.marginRows() {
+		text := l.rowText(row)
+		if pageNumberRegexp.MatchString(strings.TrimSpace(text)) || repeated[marginKey(text)] {
+			removed[row] = true
+		}
+	}
+	segments := []layoutSegment{}
+	for _, seg := range l.segments {
+		if !removed[seg.row] {
+			segments = append(segments, seg)
+		}
+	}
+
+	var found []layoutTable
+	if tables {
+		found = findTables(segments)
+	}
+	tableRows := map[int]int{}
+	textSegments := segments
+	if len(found) > 0 {
+		for i, table := range found {
+			for row := table.firstRow; row <= table.lastRow; row++ {
+				tableRows[row] = i
+			}
+		}
+		textSegments = []layoutSegment{}
+		for _, seg := range segments {
+			if _, ok := tableRows[seg.row]; !ok {
+				textSegments = append(textSegments, seg)
+			}
+		}
+	}
+
+	gutters := findGutters(textSegments)
+	paragraphs := []layoutParagraph{}
+	for _, block := range orderBlocks(segments, gutters, tableRows) {
+		if block.table >= 0 {
+			paragraphs = append(paragraphs, layoutParagraph{text: found[block.table].markdown(), table: true})
+			continue
+		}
+		paragraphs = appendParagraphs(paragraphs, block.segments)
+	}
+	return paragraphs
+}
+
+// gutter is the blank vertical strip between two columns
+type gutter struct {
+	x0, x1 float64
+}
+
+// findGutters returns the gutters between the columns of text from left to right. A gutter is a vertical strip
+// crossed by few segments, with text on both sides in the same rows, and lines of text rather than table cells.
+func findGutters(segments []layoutSegment) []gutter {
+	finite := make([]layoutSegment, 0, len(segments))
+	for _, seg := range segments {
+		if !math.IsNaN(seg.x0) && !math.IsInf(seg.x0, 0) && !math.IsNaN(seg.x1) && !math.IsInf(seg.x1, 0) && seg.x1 >= seg.x0 {
+			finite = append(finite, seg)
+		}
+	}
+	segments = finite
+	if len(segments) < 6 {
+		return nil
+	}
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	sizes := make([]float64, 0, len(segments))
+	for _, seg := range segments {
+		minX = math.Min(minX, seg.x0)
+		maxX = math.Max(maxX, seg.x1)
+		sizes = append(sizes, seg.size)
+	}
+	if maxX-minX < 50 || maxX-minX > maxLayoutWidth {
+		return nil
+	}
+
+	// the number of segments covering each point of the width
+	cover := make([]int, int(maxX-minX)+1)
+	for _, seg := range segments {
+		for b := int(seg.x0 - minX); b < int(math.Ceil(seg.x1-minX)) && b < len(cover); b++ {
+			cover[b]++
+		}
+	}
+	// the lines spanning the columns, like the titles, may cross the gutters
+	allowed := max(1, len(segments)/10)
+	minWidth := math.Max(6, 0.8*median(sizes))
+
+	candidates := []gutter{}
+	for b := 0; b < len(cover); {
+		if cover[b] > allowed {
+			b++
+			continue
+		}
+		start := b
+		lowest := cover[b]
+		for b < len(cover) && cover[b] <= allowed {
+			lowest = min(lowest, cover[b])
+			b++
+		}
+		if start == 0 || b == len(cover) {
+			continue
+		}
+		// the ends of the ragged lines may reach into the gutter, it is narrowed to the least covered part
+		end := b
+		for cover[start] > lowest {
+			start++
+		}
+		for cover[end-1] > lowest {
+			end--
+		}
+		if float64(end-start) >= minWidth {
+			candidates = append(candidates, gutter{minX + float64(start), minX + float64(end)})
+		}
+	}
+
+	gutters := []gutter{}
+	for i, g := range candidates {
+		left, right := minX, maxX
+		if i > 0 {
+			left = candidates[i-1].x1
+		}
+		if i < len(candidates)-1 {
+			right = candidates[i+1].x0
+		}
+		if isGutter(segments, g, left, right) {
+			gutters = append(gutters, g)
+		}
+	}
+	return gutters
+}
+
+// isGutter checks the text on both sides of the candidate gutter, left and right are the bounds of the columns
+func isGutter(segments []layoutSegment, g gutter, left, right float64) bool {
+	leftRows, rightRows := map[int]bool{}, map[int]bool{}
+	leftWidths, rightWidths := []float64{}, []float64{}
+	for _, seg := range segments {
+		switch {
+		case seg.x1 <= g.x0 && seg.x0 >= left:
+			leftRows[seg.row] = true
+			leftWidths = append(leftWidths, seg.x1-seg.x0)
+		case seg.x0 >= g.x1 && seg.x1 <= right:
+			rightRows[seg.row] = true
+			rightWidths = append(rightWidths, seg.x1-seg.x0)
+		}
+	}
+
+	both, either := 0, len(leftRows)
+	for row := range rightRows {
+		if leftRows[row] {
+			both++
+		} else {
+			either++
+		}
+	}
+	if both < 3 || both*10 < either*3 {
+		return false
+	}
+	// the columns of a table are made of short cells, the columns of text of long lines
+	return median(leftWidths) >= 0.3*(g.x0-left) && median(rightWidths) >= 0.3*(right-g.x1)
+}
+
+// layoutBlock is a block of lines in a column, or a table when table is not -1
+type layoutBlock struct {
+	segments []layoutSegment
+	table    int
+}
+
+// orderBlocks returns the blocks of lines in reading order. The rows between the spanning lines are read
+// column by column, a row with a line crossing a gutter is a block on its own. The rows of the tables,
+// the keys of tableRows, are replaced by a block of the table.
+func orderBlocks(segments []layoutSegment, gutters []gutter, tableRows map[int]int) []layoutBlock {
+	blocks := []layoutBlock{}
+	columns := make([][]layoutSegment, len(gutters)+1)
+	flush := func() {
+		for i, column := range columns {
+			if len(column) > 0 {
+				blocks = append(blocks, layoutBlock{segments: column, table: -1})
+			}
+			columns[i] = nil
+		}
+	}
+
+	for start := 0; start < len(segments); {
+		end := start
+		for end < len(segments) && segments[end].row == segments[start].row {
+			end++
+		}
+		row := segments[start:end]
+		start = end
+
+		if table, ok := tableRows[row[0].row]; ok {
+			flush()
+			if n := len(blocks); n == 0 || blocks[n-1].table != table {
+				blocks = append(blocks, layoutBlock{table: table})
+			}
+			continue
+		}
+
+		spanning := false
+		for _, seg := range row {
+			if columnOf(seg, gutters) < 0 {
+				spanning = true
+			}
+		}
+		if spanning {
+			flush()
+			blocks = append(blocks, layoutBlock{segments: row, table: -1})
+			continue
+		}
+		for _, seg := range row {
+			col := columnOf(seg, gutters)
+			columns[col] = append(columns[col], seg)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// columnOf returns the column of the segment, -1 when it crosses a gutter
+func columnOf(seg layoutSegment, gutters []gutter) int {
+	col := 0
+	for _, g := range gutters {
+		if seg.x0 < g.x1 && seg.x1 > g.x0 {
+			return -1
+		}
+		if seg.x0 >= g.x1 {
+			col++
+		}
+	}
+	return col
+}
+
+// layoutLine is a line of a block, the segments of a row in the same column
+type layoutLine struct {
+	x0, x1 float64
+	y      float64
+	size   float64
+	text   string
+}
+
+// blockLines merges the segments of the same row in the block
+func blockLines(block []layoutSegment) []layoutLine {
+	lines := []layoutLine{}
+	for i, seg := range block {
+		if i > 0 && seg.row == block[i-1].row {
+			line := &lines[len(lines)-1]
+			line.text += " " + seg.text
+			line.x1 = math.Max(line.x1, seg.x1)
+			line.size = math.Max(line.size, seg.size)
+			continue
+		}
+		lines = append(lines, layoutLine{x0: seg.x0, x1: seg.x1, y: seg.y, size: seg.size, text: seg.text})
+	}
+	return lines
+}
+
+// appendParagraphs joins the lines of the block into paragraphs and appends them. A paragraph ends at a
+// vertical gap wider than the line spacing or after a short line. The first line continues the previous
+// paragraph when the paragraph ends in the middle of a sentence, like at the bottom of a column.
+func appendParagraphs(paragraphs []layoutParagraph, block []layoutSegment) []layoutParagraph {
+	lines := blockLines(block)
+	spacings := []float64{}
+	left, right := math.Inf(1), math.Inf(-1)
+	for i, line := range lines {
+		if i > 0 && lines[i-1].y > line.y {
+			spacings = append(spacings, lines[i-1].y-line.y)
+		}
+		left = math.Min(left, line.x0)
+		right = math.Max(right, line.x1)
+	}
+	spacing := median(spacings)
+
+	for i, line := range lines {
+		if i == 0 {
+			n := len(paragraphs)
+			if n > 0 && !paragraphs[n-1].table && continuesParagraph(paragraphs[n-1].text, line.text) {
+				paragraphs[n-1].text = joinLines(paragraphs[n-1].text, line.text)
+			} else {
+				paragraphs = append(paragraphs, layoutParagraph{text: line.text})
+			}
+			continue
+		}
+
+		prev := lines[i-1]
+		gap := prev.y - line.y
+		newParagraph := gap > 2*math.Max(prev.size, line.size) ||
+			(len(spacings) > 1 && gap > 1.3*spacing+0.1) ||
+			(len(lines) >= 3 && prev.x1 < right-0.25*(right-left))
+		if newParagraph {
+			paragraphs = append(paragraphs, layoutParagraph{text: line.text})
+		} else {
+			n := len(paragraphs)
+			paragraphs[n-1].text = joinLines(paragraphs[n-1].text, line.text)
+		}
+	}
+	return paragraphs
+}
+
+// continuesParagraph reports whether the next line continues the paragraph: the paragraph doesn't end a sentence
+// and the line starts with a lowercase letter.
+func continuesParagraph(paragraph string, next string) bool {
+	last, _ := utf8.DecodeLastRuneInString(paragraph)
+	first, _ := utf8.DecodeRuneInString(next)
+	return !strings.ContainsRune(".!?:;。！？：；…\"”)", last) && unicode.IsLower(first)
+}
+
+// joinLines joins two lines of a paragraph, the words hyphenated at the end of the line are repaired
+func joinLines(line string, next string) string {
+	if strings.HasSuffix(line, "\u00ad") {
+		return strings.TrimSuffix(line, "\u00ad") + next
+	}
+	last, _ := utf8.DecodeLastRuneInString(line)
+	first, _ := utf8.DecodeRuneInString(next)
+	if last == '-' {
+		beforeHyphen, _ := utf8.DecodeLastRuneInString(strings.TrimSuffix(line, "-"))
+		if unicode.IsLetter(beforeHyphen) && unicode.IsLower(first) {
+			return strings.TrimSuffix(line, "-") + next
+		}
+	}
+	if isCJK(last) || isCJK(first) {
+		return line + next
+	}
+	return line + " " + next
+}
+
+// median returns the median of the values, zero when there are none
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return sorted[len(sorted)/2]
+}
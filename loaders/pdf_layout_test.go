@@ -0,0 +1,111 @@
+package loaders
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+// textAt returns the characters of the text drawn from x, y, each character is half of the font size wide
+func textAt(s string, x, y float64) []pdf.Text {
+	texts := []pdf.Text{}
+	for _, r := range s {
+		texts = append(texts, pdf.Text{FontSize: 10, X: x, Y: y, W: 5, S: string(r)})
+		x += 5
+	}
+	return texts
+}
+
+// twoColumnPage returns a page with a header, a title spanning the columns, two columns and a page number
+func twoColumnPage(num string) []pdf.Text {
+	lines := []struct {
+		text string
+		x, y float64
+	}{
+		{"Annual Report " + num, 50, 770},
+		{"Reading Order of the Columns Restored From the Positions", 50, 700},
+		{"Layout extraction reads the left col-", 50, 690},
+		{"umn first and then the right column,", 50, 678},
+		{"from the top of the column to the", 50, 666},
+		{"the right column comes after the left", 320, 690},
+		{"one even though the rows are shared.", 320, 678},
+		{"Headers and footers are removed too.", 320, 666},
+		{"Page " + num + " of 2", 280, 30},
+	}
+	texts := []pdf.Text{}
+	for _, line := range lines {
+		texts = append(texts, textAt(line.text, line.x, line.y)...)
+	}
+	return texts
+}
+
+func TestPageLayout(t *testing.T) {
+	t.Parallel()
+
+	box := pdf.Rect{Max: pdf.Point{X: 612, Y: 792}}
+	page1 := newPageLayout(twoColumnPage("1"), nil, box)
+	page2 := newPageLayout(twoColumnPage("2"), nil, box)
+
+	repeated := map[string]bool{}
+	for _, key := range page1.marginKeys() {
+		for _, other := range page2.marginKeys() {
+			if key == other {
+				repeated[key] = true
+			}
+		}
+	}
+	assert.True(t, repeated["annual report #"])
+
+	text := joinParagraphs(page1.paragraphs(repeated, false))
+	assert.Equal(t, "Reading Order of the Columns Restored From the Positions\n\n"+
+		"Layout extraction reads the left column first and then the right column, from the top of the column to the "+
+		"the right column comes after the left one even though the rows are shared. Headers and footers are removed too.", text)
+	assert.NotContains(t, joinParagraphs(page1.paragraphs(nil, false)), "Page 1")
+	assert.Contains(t, joinParagraphs(page1.paragraphs(nil, false)), "Annual Report 1")
+
+	// the columns without a title, the ends of the lines are ragged
+	texts := []pdf.Text{}
+	for _, text := range twoColumnPage("1") {
+		if text.Y != 700 && text.Y != 770 {
+			texts = append(texts, text)
+		}
+	}
+	assert.Equal(t, "Layout extraction reads the left column first and then the right column, from the top of the column to the "+
+		"the right column comes after the left one even though the rows are shared. Headers and footers are removed too.",
+		joinParagraphs(newPageLayout(texts, nil, box).paragraphs(nil, false)))
+}
+
+func TestPageLayoutFarCoordinates(t *testing.T) {
+	t.Parallel()
+
+	box := pdf.Rect{Max: pdf.Point{X: 612, Y: 792}}
+	for _, x := range []float64{1e12, -1e12, math.NaN(), math.Inf(1)} {
+		texts := append(twoColumnPage("1"), pdf.Text{FontSize: 10, X: x, Y: 600, W: 5, S: "x"})
+		text := joinParagraphs(newPageLayout(texts, nil, box).paragraphs(nil, false))
+		assert.Contains(t, text, "Reading Order of the Columns", x)
+	}
+}
+
+func TestPageLayoutWithoutWidths(t *testing.T) {
+	t.Parallel()
+
+	// the fonts without widths draw all the characters of a text at the same position
+	texts := textAt("Hello world", 50, 700)
+	for i := range texts {
+		texts[i].X, texts[i].W = 50, 0
+	}
+	texts = append(texts, textAt("Second line", 50, 688)...)
+	assert.Equal(t, "Hello world Second line", joinParagraphs(newPageLayout(texts, nil, pdf.Rect{}).paragraphs(nil, false)))
+}
+
+func TestJoinLines(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "hyphenated words", joinLines("hyphen-", "ated words"))
+	assert.Equal(t, "well- Known", joinLines("well-", "Known"))
+	assert.Equal(t, "soft hyphen", joinLines("soft hy\u00ad", "phen"))
+	assert.Equal(t, "中文文本", joinLines("中文", "文本"))
+	assert.Equal(t, "two lines", joinLines("two", "lines"))
+}
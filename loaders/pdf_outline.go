@@ -0,0 +1,212 @@
+package loaders
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// pdfInfoKeys are the fields of the Info dictionary added to the metadata of the pages
+var pdfInfoKeys = []struct{ key, name string }{
+	{"Title", "title"},
+	{"Author", "author"},
+	{"Subject", "subject"},
+	{"Keywords", "keywords"},
+	{"CreationDate", "creation_date"},
+	{"Producer", "producer"},
+}
+
+// pdfDateRegexp matches the dates of the Info dictionary, e.g. "D:20230415103000+08'00'"
+var pdfDateRegexp = regexp.MustCompile(`^(?:D:)?(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?(?:([Zz])|([+-])(\d{2})'?(\d{2})?'?)?`)
+
+// maxOutlineDepth limits the depth of the outline tree, malformed files may have loops
+const maxOutlineDepth = 32
+
+// maxOutlineEntries limits the number of items read from the outline, the /Next and /First links
+// of the malformed files may have cycles
+const maxOutlineEntries = 10000
+
+// outlineEntry is a bookmark of the outline, path is the titles from the top level entry to the bookmark
+type outlineEntry struct {
+	page int
+	path []string
+}
+
+// documentInfo returns the fields of the Info dictionary of the PDF, the dates are converted to RFC 3339.
+// The fields that are not set are left out.
+func documentInfo(reader *pdf.Reader) (info map[string]any) {
+	info = map[string]any{}
+	// the pdf reader panics on the malformed objects, the info is optional
+	defer func() {
+		if r := recover(); r != nil {
+			info = map[string]any{}
+		}
+	}()
+
+	dict := reader.Trailer().Key("Info")
+	for _, field := range pdfInfoKeys {
+		value := strings.TrimSpace(dict.Key(field.key).Text())
+		if value == "" {
+			continue
+		}
+		if field.key == "CreationDate" {
+			if date, err := parsePDFDate(value); err == nil {
+				value = date.Format(time.RFC3339)
+			}
+		}
+		info[field.name] = value
+	}
+	return info
+}
+
+// parsePDFDate parses a date of the Info dictionary, the missing parts default to the start of the period
+func parsePDFDate(s string) (time.Time, error) {
+	m := pdfDateRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	num := func(s string, def int) int {
+		if s == "" {
+			return def
+		}
+		n := 0
+		for _, c := range s {
+			n = n*10 + int(c-'0')
+		}
+		return n
+	}
+
+	loc := time.UTC
+	if m[8] != "" {
+		offset := (num(m[9], 0)*60 + num(m[10], 0)) * 60
+		if m[8] == "-" {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+	date := time.Date(num(m[1], 0), time.Month(num(m[2], 1)), num(m[3], 1), num(m[4], 0), num(m[5], 0), num(m[6], 0), 0, loc)
+	if date.Month() != time.Month(num(m[2], 1)) || date.Day() != num(m[3], 1) {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return date, nil
+}
+
+// documentOutline returns the bookmarks of the outline pointing to a page of the document, sorted by page.
+// The bookmarks of the same page keep the order of the outline.
+func documentOutline(reader *pdf.Reader) (entries []outlineEntry) {
+	// the pdf reader panics on the malformed objects, the outline is optional
+	defer func() {
+		if r := recover(); r != nil {
+			entries = nil
+		}
+	}()
+
+	root := reader.Trailer().Key("Root")
+	first := root.Key("Outlines").Key("First")
+	if first.Kind() != pdf.Dict {
+		return nil
+	}
+
+	// the destinations point to the page dictionaries, they are told apart by their content
+	pages := map[string]int{}
+	for i := 1; i <= reader.NumPage(); i++ {
+		if v := reader.Page(i).V; !v.IsNull() {
+			pages[v.String()] = i
+		}
+	}
+
+	// visited counts the items read, with or without a destination, so the cycles end
+	visited := 0
+	var walk func(item pdf.Value, path []string, depth int)
+	walk = func(item pdf.Value, path []string, depth int) {
+		for ; item.Kind() == pdf.Dict && visited < maxOutlineEntries; item = item.Key("Next") {
+			visited++
+			title := strings.Join(strings.Fields(item.Key("Title").Text()), " ")
+			itemPath := append(path[:len(path):len(path)], title)
+			if page := destinationPage(root, outlineDestination(item), pages); page > 0 {
+				entries = append(entries, outlineEntry{page: page, path: itemPath})
+			}
+			if depth < maxOutlineDepth {
+				walk(item.Key("First"), itemPath, depth+1)
+			}
+		}
+	}
+	walk(first, nil, 1)
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].page < entries[j].page })
+	return entries
+}
+
+// outlineDestination returns the destination of the bookmark, set directly or by a GoTo action
+func outlineDestination(item pdf.Value) pdf.Value {
+	if dest := item.Key("Dest"); !dest.IsNull() {
+		return dest
+	}
+	if action := item.Key("A"); action.Key("S").Name() == "GoTo" {
+		return action.Key("D")
+	}
+	return pdf.Value{}
+}
+
+// destinationPage returns the page number of the destination, zero when it is not found.
+// The named destinations are looked up in the Dests dictionary and the Dests name tree of the catalog.
+func destinationPage(root pdf.Value, dest pdf.Value, pages map[string]int) int {
+	switch dest.Kind() {
+	case pdf.Name:
+		dest = root.Key("Dests").Key(dest.Name())
+	case pdf.String:
+		dest = lookupName(root.Key("Names").Key("Dests"), dest.RawString(), 0)
+	}
+	if dest.Kind() == pdf.Dict {
+		dest = dest.Key("D")
+	}
+	if dest.Kind() != pdf.Array || dest.Len() == 0 {
+		return 0
+	}
+
+	page := dest.Index(0)
+	if page.Kind() == pdf.Integer {
+		// the page index of the remote destinations
+		return int(page.Int64()) + 1
+	}
+	return pages[page.String()]
+}
+
+// lookupName returns the value of the key in the name tree
+func lookupName(node pdf.Value, key string, depth int) pdf.Value {
+	if node.Kind() != pdf.Dict || depth > maxOutlineDepth {
+		return pdf.Value{}
+	}
+	names := node.Key("Names")
+	for i := 0; i+1 < names.Len(); i += 2 {
+		if names.Index(i).RawString() == key {
+			return names.Index(i + 1)
+		}
+	}
+	kids := node.Key("Kids")
+	for i := 0; i < kids.Len(); i++ {
+		kid := kids.Index(i)
+		if limits := kid.Key("Limits"); limits.Len() == 2 &&
+			(key < limits.Index(0).RawString() || key > limits.Index(1).RawString()) {
+			continue
+		}
+		if value := lookupName(kid, key, depth+1); !value.IsNull() {
+			return value
+		}
+	}
+	return pdf.Value{}
+}
+
+// sectionOf returns the heading path of the page, the path of the last bookmark at or before the page.
+// It is nil when the page is before the first bookmark.
+func sectionOf(entries []outlineEntry, page int) []string {
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].page > page })
+	if i == 0 {
+		return nil
+	}
+	return entries[i-1].path
+}
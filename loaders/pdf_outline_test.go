@@ -0,0 +1,104 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"loader/schema"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPDFOutline(t *testing.T) {
+	t.Parallel()
+
+	page := "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 9 0 R >> >> /Contents %d 0 R >>"
+	data := buildPDF("/Info 10 0 R",
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 11 0 R /Dests << /rev [5 0 R /Fit] >> "+
+			"/Names << /Dests << /Names [(results) << /D [4 0 R /Fit] >>] >> >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>",
+		fmt.Sprintf(page, 6), fmt.Sprintf(page, 7), fmt.Sprintf(page, 8),
+		pdfText("Introduction"), pdfText("Results"), pdfText("Revenue"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Title (Annual Report) /Author (Jane Doe) /CreationDate (D:20230415103000+08'00') /Producer (Test) >>",
+		"<< /Type /Outlines /First 12 0 R /Last 13 0 R /Count 3 >>",
+		"<< /Title (1 Intro) /Parent 11 0 R /Next 13 0 R /Dest [3 0 R /Fit] >>",
+		"<< /Title (2 Results) /Parent 11 0 R /Prev 12 0 R /First 14 0 R /Last 14 0 R /A << /S /GoTo /D (results) >> >>",
+		"<< /Title (2.1  Revenue) /Parent 13 0 R /Dest /rev >>",
+	)
+
+	docs, err := NewPDF(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+
+	assert.Equal(t, "Introduction\n", docs[0].PageContent)
+	for _, doc := range docs {
+		assert.Equal(t, "Annual Report", doc.Metadata["title"])
+		assert.Equal(t, "Jane Doe", doc.Metadata["author"])
+		assert.Equal(t, "2023-04-15T10:30:00+08:00", doc.Metadata["creation_date"])
+		assert.Equal(t, "Test", doc.Metadata["producer"])
+		assert.NotContains(t, doc.Metadata, "subject")
+	}
+	assert.Equal(t, []string{"1 Intro"}, docs[0].Metadata["section_path"])
+	assert.Equal(t, "1 Intro", docs[0].Metadata["section"])
+	assert.Equal(t, []string{"2 Results"}, docs[1].Metadata["section_path"])
+	assert.Equal(t, []string{"2 Results", "2.1 Revenue"}, docs[2].Metadata["section_path"])
+	assert.Equal(t, "2.1 Revenue", docs[2].Metadata["section"])
+}
+
+func TestPDFOutlineCycle(t *testing.T) {
+	t.Parallel()
+
+	// the items link to themselves and have no destination
+	data := buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 5 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 7 0 R >> >> /Contents 4 0 R >>",
+		pdfText("Introduction"),
+		"<< /Type /Outlines /First 6 0 R /Last 6 0 R /Count 1 >>",
+		"<< /Title (Loop) /Parent 5 0 R /Next 6 0 R /First 6 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+
+	done := make(chan struct{})
+	var docs []schema.Document
+	var err error
+	go func() {
+		defer close(done)
+		docs, err = NewPDF(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("reading the outline did not stop")
+	}
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Introduction\n", docs[0].PageContent)
+	assert.NotContains(t, docs[0].Metadata, "section")
+}
+
+func TestParsePDFDate(t *testing.T) {
+	t.Parallel()
+
+	date, err := parsePDFDate("D:20230415103000Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 4, 15, 10, 30, 0, 0, time.UTC), date)
+
+	date, err = parsePDFDate("D:2023")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), date)
+
+	date, err = parsePDFDate("D:20230415103000-05'30'")
+	require.NoError(t, err)
+	assert.Equal(t, "2023-04-15T10:30:00-05:30", date.Format(time.RFC3339))
+
+	_, err = parsePDFDate("yesterday")
+	assert.Error(t, err)
+	_, err = parsePDFDate("D:20230231")
+	assert.Error(t, err)
+}
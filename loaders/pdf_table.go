@@ -0,0 +1,125 @@
+package loaders
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// columnSpan is the horizontal extent of a column of a table
+type columnSpan struct {
+	x0, x1 float64
+}
+
+// layoutTable is a table found in the rows of a page
+type layoutTable struct {
+	firstRow, lastRow int
+	cells             [][]string
+}
+
+// tableRow is the segments of a row of a table candidate
+type tableRow struct {
+	row      int
+	y, size  float64
+	segments []layoutSegment
+}
+
+// findTables returns the tables of the page. A table is a run of close rows with several segments each,
+// the cells of the rows are aligned into columns. The rows of text columns are not tables, their cells are long.
+func findTables(segments []layoutSegment) []layoutTable {
+	rows := []tableRow{}
+	for _, seg := range segments {
+		if n := len(rows); n > 0 && rows[n-1].row == seg.row {
+			rows[n-1].segments = append(rows[n-1].segments, seg)
+			rows[n-1].size = math.Max(rows[n-1].size, seg.size)
+			continue
+		}
+		rows = append(rows, tableRow{row: seg.row, y: seg.y, size: seg.size, segments: []layoutSegment{seg}})
+	}
+
+	tables := []layoutTable{}
+	for i := 0; i < len(rows); {
+		if len(rows[i].segments) < 2 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(rows) && len(rows[j].segments) >= 2 &&
+			rows[j-1].y-rows[j].y <= 2.5*math.Max(rows[j-1].size, rows[j].size) {
+			j++
+		}
+		if table, ok := newTable(rows[i:j]); ok {
+			tables = append(tables, table)
+		}
+		i = j
+	}
+	return tables
+}
+
+// newTable aligns the cells of the rows into columns, ok is false when the rows don't look like a table
+func newTable(rows []tableRow) (layoutTable, bool) {
+	columns, widths := tableColumns(rows)
+	if len(columns) < 2 || len(rows) < 2 || (len(columns) == 2 && len(rows) < 3) {
+		return layoutTable{}, false
+	}
+	left, right := columns[0].x0, columns[len(columns)-1].x1
+	if width := median(widths); len(columns) == 2 && width > 0.35*(right-left) && width > 10*rows[0].size {
+		// two columns of text, their lines are long
+		return layoutTable{}, false
+	}
+
+	table := layoutTable{firstRow: rows[0].row, lastRow: rows[len(rows)-1].row}
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for _, seg := range row.segments {
+			for c, column := range columns {
+				if seg.x0 < column.x1 && seg.x1 > column.x0 {
+					cells[c] = strings.TrimSpace(cells[c] + " " + seg.text)
+					break
+				}
+			}
+		}
+		table.cells = append(table.cells, cells)
+	}
+	return table, true
+}
+
+// tableColumns merges the overlapping segments of the rows into the columns of the table,
+// the widths of the segments are returned too.
+func tableColumns(rows []tableRow) ([]columnSpan, []float64) {
+	spans := []columnSpan{}
+	widths := []float64{}
+	for _, row := range rows {
+		for _, seg := range row.segments {
+			spans = append(spans, columnSpan{seg.x0, seg.x1})
+			widths = append(widths, seg.x1-seg.x0)
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].x0 < spans[j].x0 })
+
+	columns := []columnSpan{}
+	for _, span := range spans {
+		if n := len(columns); n > 0 && span.x0 <= columns[n-1].x1 {
+			columns[n-1].x1 = math.Max(columns[n-1].x1, span.x1)
+			continue
+		}
+		columns = append(columns, span)
+	}
+	return columns, widths
+}
+
+// markdown returns the table in markdown, the first row is the header
+func (t layoutTable) markdown() string {
+	var sb strings.Builder
+	for i, row := range t.cells {
+		sb.WriteString("|")
+		for _, cell := range row {
+			sb.WriteString(" " + strings.ReplaceAll(cell, "|", "\\|") + " |")
+		}
+		sb.WriteString("\n")
+		if i == 0 {
+			sb.WriteString(strings.Repeat("| --- ", len(row)) + "|\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
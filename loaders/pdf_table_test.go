@@ -0,0 +1,73 @@
+package loaders
+
+import (
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+// tablePage returns a page with a paragraph, a table of three columns and a paragraph
+func tablePage() []pdf.Text {
+	lines := []struct {
+		text string
+		x, y float64
+	}{
+		{"The prices of the plans are listed below.", 50, 700},
+		{"Plan", 50, 670}, {"Price", 200, 670}, {"Users", 350, 670},
+		{"Basic", 50, 655}, {"10", 200, 655}, {"1", 350, 655},
+		{"Team", 50, 640}, {"40 | 50", 200, 640}, {"10", 350, 640},
+		{"All the prices are monthly.", 50, 610},
+	}
+	texts := []pdf.Text{}
+	for _, line := range lines {
+		texts = append(texts, textAt(line.text, line.x, line.y)...)
+	}
+	return texts
+}
+
+func TestPageLayoutTables(t *testing.T) {
+	t.Parallel()
+
+	box := pdf.Rect{Max: pdf.Point{X: 612, Y: 792}}
+	paragraphs := newPageLayout(tablePage(), nil, box).paragraphs(nil, true)
+	if assert.Len(t, paragraphs, 3) {
+		assert.Equal(t, layoutParagraph{text: "The prices of the plans are listed below."}, paragraphs[0])
+		assert.Equal(t, layoutParagraph{
+			text:  "| Plan | Price | Users |\n| --- | --- | --- |\n| Basic | 10 | 1 |\n| Team | 40 \\| 50 | 10 |",
+			table: true,
+		}, paragraphs[1])
+		assert.Equal(t, layoutParagraph{text: "All the prices are monthly."}, paragraphs[2])
+	}
+
+	// the tables are not detected without the option
+	for _, paragraph := range newPageLayout(tablePage(), nil, box).paragraphs(nil, false) {
+		assert.False(t, paragraph.table)
+	}
+
+	// the columns of text are not tables
+	for _, paragraph := range newPageLayout(twoColumnPage("1"), nil, box).paragraphs(nil, true) {
+		assert.False(t, paragraph.table)
+	}
+}
+
+func TestPageLayoutTableRules(t *testing.T) {
+	t.Parallel()
+
+	// the cells are too close to be cut at the gaps, they are cut at the borders
+	texts := []pdf.Text{}
+	rects := []pdf.Rect{}
+	for i, row := range [][]string{{"Name", "Qty"}, {"Apple", "3"}, {"Pear", "12"}} {
+		y := 700 - float64(i)*15
+		texts = append(texts, textAt(row[0], 50, y)...)
+		texts = append(texts, textAt(row[1], 82, y)...)
+	}
+	rects = append(rects, pdf.Rect{Min: pdf.Point{X: 80, Y: 660}, Max: pdf.Point{X: 80.5, Y: 715}})
+
+	box := pdf.Rect{Max: pdf.Point{X: 612, Y: 792}}
+	assert.Equal(t, "Name Qty Apple 3 Pear 12", joinParagraphs(newPageLayout(texts, nil, box).paragraphs(nil, false)))
+	assert.Equal(t, []layoutParagraph{{
+		text:  "| Name | Qty |\n| --- | --- |\n| Apple | 3 |\n| Pear | 12 |",
+		table: true,
+	}}, newPageLayout(texts, rects, box).paragraphs(nil, true))
+}
@@ -1,10 +1,13 @@
 package loaders
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"testing"
 
+	"loader/schema"
 	"loader/textsplitter"
 
 	"github.com/ledongthuc/pdf"
@@ -139,3 +142,131 @@ func TestPDFTextSplit(t *testing.T) {
 		}
 	})
 }
+
+func TestPDFPages(t *testing.T) {
+	t.Parallel()
+
+	// the third page fails to read, its content is not valid zlib data
+	page := "<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 8 0 R >> >> /Contents %d 0 R >>"
+	data := buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>",
+		fmt.Sprintf(page, 6), fmt.Sprintf(page, 7), fmt.Sprintf(page, 9),
+		pdfText("One"), pdfText("Two"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Length 5 /Filter /FlateDecode >>\nstream\nxxxxx\nendstream",
+	)
+
+	load := func(opts ...PDFOptions) ([]schema.Document, error) {
+		return NewPDF(bytes.NewReader(data), int64(len(data)), opts...).Load(context.Background())
+	}
+
+	_, err := load()
+	require.ErrorIs(t, err, ErrCorrupt)
+	assert.Contains(t, err.Error(), "page 3")
+
+	docs, err := load(PdfWithPages("2"))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Two\n", docs[0].PageContent)
+	assert.Equal(t, 2, docs[0].Metadata["page"])
+	assert.Equal(t, 3, docs[0].Metadata["total_pages"])
+
+	failed := map[int]error{}
+	docs, err = load(PdfWithPages("2-,1"), PdfWithPageErrorHandler(func(page int, err error) { failed[page] = err }))
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "One\n", docs[0].PageContent)
+	assert.Equal(t, "Two\n", docs[1].PageContent)
+	require.Len(t, failed, 1)
+	assert.ErrorIs(t, failed[3], ErrCorrupt)
+
+	_, err = load(PdfWithPages("3-1"))
+	require.Error(t, err)
+}
+
+func TestPDFImagePages(t *testing.T) {
+	t.Parallel()
+
+	// the second page only draws an image, the third page is blank
+	data := buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 7 0 R >> >> /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Fm1 9 0 R >> >> /Contents 8 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << >> /Contents 8 0 R >>",
+		pdfText("Text"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		pdfStream("q 612 0 0 792 0 0 cm /Fm1 Do Q"),
+		"<< /Type /XObject /Subtype /Form /BBox [0 0 1 1] /Resources << /XObject << /Im1 10 0 R >> >> /Length 0 >>\nstream\n\nendstream",
+		"<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 /Length 1 >>\nstream\n\x00\nendstream",
+	)
+
+	docs, err := NewPDF(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, []int{2}, docs[0].Metadata["image_pages"])
+	assert.InDelta(t, 1.0/3, docs[0].Metadata["scanned_ratio"], 1e-9)
+
+	docs, err = NewPDF(bytes.NewReader(data), int64(len(data)), PdfWithImagePlaceholders(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, 2, docs[1].Metadata["page"])
+	assert.Equal(t, true, docs[1].Metadata["image_only"])
+	assert.Empty(t, docs[1].PageContent)
+	assert.NotContains(t, docs[0].Metadata, "image_only")
+
+	docs, err = NewPDF(bytes.NewReader(data), int64(len(data)), PdfWithPages("1")).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.NotContains(t, docs[0].Metadata, "image_pages")
+	assert.Equal(t, 0.0, docs[0].Metadata["scanned_ratio"])
+}
+
+func TestParsePageRanges(t *testing.T) {
+	t.Parallel()
+
+	ranges, err := ParsePageRanges(" 1-5, 10,20- ")
+	require.NoError(t, err)
+	assert.Equal(t, []PageRange{{From: 1, To: 5}, {From: 10, To: 10}, {From: 20}}, ranges)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 10, 20, 21}, selectPages(ranges, 21))
+	assert.Equal(t, []int{1, 2}, selectPages(nil, 2))
+
+	ranges, err = ParsePageRanges("")
+	require.NoError(t, err)
+	assert.Empty(t, ranges)
+
+	for _, s := range []string{"0", "a", "1-b", "5-2", "1,,2", "-3"} {
+		_, err := ParsePageRanges(s)
+		assert.Error(t, err, s)
+	}
+}
+
+// buildPDF returns a PDF of the objects numbered from 1, the first object is the catalog.
+// The entries of trailer are added to the trailer dictionary.
+func buildPDF(trailer string, objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, xref)
+	return buf.Bytes()
+}
+
+// pdfStream returns a stream object of the content
+func pdfStream(content string) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+}
+
+// pdfText returns the content stream drawing the text with the font F1
+func pdfText(text string) string {
+	return pdfStream(fmt.Sprintf("BT /F1 12 Tf 72 700 Td (%s) Tj ET", text))
+}
@@ -0,0 +1,72 @@
+package loaders
+
+import (
+	"context"
+	"io"
+	"iter"
+	"strings"
+
+	"loader/ppt"
+	"loader/schema"
+	"loader/textsplitter"
+)
+
+// PPT loads the text of the PowerPoint 97-2003 binary presentations.
+type PPT struct {
+	r io.ReaderAt
+}
+
+var _ IterLoader = PPT{}
+
+// NewPPT creates a new ppt loader with an io.ReaderAt.
+func NewPPT(r io.ReaderAt) PPT {
+	return PPT{r: r}
+}
+
+// Load reads from the io.ReaderAt and returns a document for each slide with text.
+func (p PPT) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, p.LoadIter(ctx))
+}
+
+// LoadIter yields a document for each slide with text, the texts of the slide are written one per line.
+func (p PPT) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(schema.Document{}, err)
+			return
+		}
+		slides, err := ppt.Read(p.r)
+		if err != nil {
+			yield(schema.Document{}, compoundError(err))
+			return
+		}
+
+		texts := make([]string, 0, len(slides))
+		for _, slide := range slides {
+			if len(slide) > 0 {
+				texts = append(texts, strings.Join(slide, "\n")+"\n")
+			}
+		}
+		docs := make([]schema.Document, len(texts))
+		for i, text := range texts {
+			docs[i] = schema.Document{
+				PageContent: text,
+				Metadata: map[string]any{
+					"slide":        i,
+					"total_slides": len(texts),
+				},
+			}
+		}
+		yieldAll(ctx, docs, yield)
+	}
+}
+
+// LoadAndSplit reads text data from the io.ReaderAt and splits it into multiple
+// documents using a text splitter.
+func (p PPT) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := p.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
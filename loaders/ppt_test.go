@@ -0,0 +1,66 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"loader/internal/cfbtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pptRecord returns a record of the presentation, verInstance is 0x0F for the containers
+func pptRecord(verInstance uint16, typ uint16, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+	return append(le(verInstance, typ, uint32(len(body))), body...)
+}
+
+// presentationBytes returns a presentation with the slide list and the slides with the texts of their shapes
+func presentationBytes(slideList []byte, slides [][]byte, userEditSize int) []byte {
+	var stream []byte
+	offsets := le(uint32(len(slides))<<20 | 1)
+	for _, slide := range slides {
+		offsets = append(offsets, le(uint32(len(stream)))...)
+		stream = append(stream, pptRecord(0x0F, 0x03EE, slide)...)
+	}
+	stream = append(stream, pptRecord(0x0F, 0x03E8, slideList)...)
+	stream = append(stream, pptRecord(0, 0x0FF5, make([]byte, userEditSize))...)
+	stream = append(stream, pptRecord(0, 0x1772, offsets)...)
+	return cfbtest.File(cfbtest.Stream{Name: "PowerPoint Document", Data: stream})
+}
+
+func TestPPT(t *testing.T) {
+	t.Parallel()
+
+	slideList := bytes.Join([][]byte{
+		pptRecord(0x0F, 0x0FF0,
+			pptRecord(0, 0x03F3, le(uint32(1)), make([]byte, 16)),
+			pptRecord(0, 0x0F9F, le(uint32(0))),
+			pptRecord(0, 0x0FA0, wideChars("标题\rLine")),
+			pptRecord(0, 0x03F3, le(uint32(2)), make([]byte, 16)),
+			pptRecord(0, 0x0FA8, []byte("Second")),
+			pptRecord(0, 0x03F3, le(uint32(3)), make([]byte, 16)),
+		),
+		// the slide list of the notes
+		pptRecord(0x2F, 0x0FF0,
+			pptRecord(0, 0x03F3, le(uint32(4)), make([]byte, 16)),
+			pptRecord(0, 0x0FA8, []byte("Notes")),
+		),
+	}, nil)
+	slides := [][]byte{
+		pptRecord(0x0F, 0xF002, pptRecord(0x0F, 0xF00D, pptRecord(0, 0x0FA0, wideChars("标题\rLine")))),
+		pptRecord(0x0F, 0xF002, pptRecord(0x0F, 0xF00D, pptRecord(0, 0x0FA8, []byte("Shape\vtext ")))),
+		pptRecord(0x0F, 0xF002),
+	}
+	docs, err := NewPPT(bytes.NewReader(presentationBytes(slideList, slides, 28))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "标题\nLine\n", docs[0].PageContent)
+	assert.Equal(t, "Second\nShape\ntext\n", docs[1].PageContent)
+	assert.Equal(t, map[string]any{"slide": 1, "total_slides": 2}, docs[1].Metadata)
+
+	_, err = NewPPT(bytes.NewReader(presentationBytes(slideList, slides, 32))).Load(context.Background())
+	require.ErrorIs(t, err, ErrEncrypted)
+}
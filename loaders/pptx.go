@@ -3,6 +3,9 @@ package loaders
 import (
 	"context"
 	"io"
+	"iter"
+	"strings"
+
 	"loader/pptx"
 	"loader/schema"
 	"loader/textsplitter"
@@ -10,48 +13,133 @@ import (
 
 // HTML loads parses and sanitizes html content from an io.Reader.
 type PPTX struct {
-	r io.ReaderAt
-	s int64
+	r     io.ReaderAt
+	s     int64
+	ocr   OCRProvider
+	notes bool
 }
 
-var _ Loader = PPTX{}
+var _ IterLoader = PPTX{}
 
-// NewHTML creates a new html loader with an io.Reader.
-func NewPPTX(r io.ReaderAt, size int64) PPTX {
-	return PPTX{r, size}
+// PPTXOptions are options for the pptx loader.
+type PPTXOptions func(pptx *PPTX)
+
+// PptxWithOCR recognizes the text of the pictures of the slides with the OCR provider, the text is added
+// after the text of the slide and the document has the metadata "ocr" set to true.
+func PptxWithOCR(ocr OCRProvider) PPTXOptions {
+	return func(pptx *PPTX) {
+		pptx.ocr = ocr
+	}
 }
 
-// Load reads from the io.Reader and returns a single document with the data.
-func (d PPTX) Load(_ context.Context) ([]schema.Document, error) {
+// PptxWithNotes appends the speaker notes of the slides after their text, following a "Notes:" line.
+func PptxWithNotes(notes bool) PPTXOptions {
+	return func(pptx *PPTX) {
+		pptx.notes = notes
+	}
+}
 
-	alltexts, err := pptx.Read(d.r, d.s)
-	if err != nil {
-		return nil, err
+// NewHTML creates a new html loader with an io.Reader.
+func NewPPTX(r io.ReaderAt, size int64, opts ...PPTXOptions) PPTX {
+	d := PPTX{r: r, s: size}
+	for _, opt := range opts {
+		opt(&d)
 	}
+	return d
+}
+
+// Load reads from the io.Reader and returns a document for each slide with text.
+func (d PPTX) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, d.LoadIter(ctx))
+}
 
-	docs := []schema.Document{}
-	slides := make([]string, 0)
-	for _, p := range alltexts {
-		line := ""
-		for _, t := range p {
-			line += t + ""
+// LoadIter reads the texts of the slides in the order of the presentation and yields a document for each
+// slide with text. The metadata has the 1-based number of the slide in the presentation, the text of its
+// title placeholder and whether the slide is hidden.
+func (d PPTX) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(schema.Document{}, err)
+			return
 		}
-		if line != "" {
-			slides = append(slides, line)
+		allslides, err := pptx.ReadSlides(d.r, d.s, d.notes)
+		if err != nil {
+			yield(schema.Document{}, openError(d.r, err))
+			return
 		}
 
+		var media [][]pptx.Media
+		if d.ocr != nil {
+			media, err = pptx.ReadMedia(d.r, d.s)
+			if err != nil {
+				yield(schema.Document{}, openError(d.r, err))
+				return
+			}
+		}
+
+		slides := make([]pptx.Slide, 0)
+		slideTexts := make([]string, 0)
+		slideImages := make([][]imageFile, 0)
+		for n, slide := range allslides {
+			line := ""
+			for _, t := range slide.Texts {
+				line += t + ""
+			}
+			images := []imageFile{}
+			if n < len(media) {
+				for _, m := range media[n] {
+					if mime := imageMIME(m.Name); mime != "" {
+						images = append(images, imageFile{name: m.Name, mime: mime, data: m.Data})
+					}
+				}
+			}
+			if line != "" || slide.Notes != "" || len(images) > 0 {
+				slides = append(slides, slide)
+				slideTexts = append(slideTexts, line)
+				slideImages = append(slideImages, images)
+			}
+
+		}
+		// the slides are numbered in the presentation, with the slides without text
+		numPages := len(allslides)
+		for i, slide := range slides {
+			if err := ctx.Err(); err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+			doc := schema.Document{
+				PageContent: slideTexts[i],
+				Metadata: map[string]any{
+					"slide":        slide.Number - 1,
+					"total_slides": numPages,
+					"slide_number": slide.Number,
+				},
+			}
+			if slide.Title != "" {
+				doc.Metadata["slide_title"] = slide.Title
+			}
+			if slide.Hidden {
+				doc.Metadata["hidden"] = true
+			}
+			if len(slideImages[i]) > 0 {
+				text, err := recognizeImages(ctx, d.ocr, slideImages[i])
+				if err != nil {
+					yield(schema.Document{}, err)
+					return
+				}
+				if text != "" {
+					doc.PageContent = strings.TrimSuffix(doc.PageContent, "\n") + "\n" + text + "\n"
+					doc.Metadata["ocr"] = true
+				}
+			}
+			if slide.Notes != "" {
+				doc.PageContent = strings.TrimSuffix(doc.PageContent, "\n") + "\n\nNotes:\n" + slide.Notes + "\n"
+			}
+			if !yield(doc, nil) {
+				return
+			}
+		}
 	}
-	numPages := len(slides)
-	for i, v := range slides {
-		docs = append(docs, schema.Document{
-			PageContent: v,
-			Metadata: map[string]any{
-				"slide":        i,
-				"total_slides": numPages,
-			},
-		})
-	}
-	return docs, nil
 }
 
 // LoadAndSplit reads text data from the io.Reader and splits it into multiple
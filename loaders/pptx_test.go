@@ -0,0 +1,69 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pptxSlide returns a slide with a title placeholder and a text box
+func pptxSlide(attrs, title, body string) string {
+	return `<p:sld xmlns:p="p" xmlns:a="a"` + attrs + `><p:cSld><p:spTree>` +
+		`<p:sp><p:nvSpPr><p:nvPr><p:ph type="title"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>` + title + `</a:t></a:r></a:p></p:txBody></p:sp>` +
+		`<p:sp><p:nvSpPr><p:nvPr/></p:nvSpPr><p:txBody><a:p><a:r><a:t>` + body + `</a:t></a:r></a:p></p:txBody></p:sp>` +
+		`</p:spTree></p:cSld></p:sld>`
+}
+
+func TestPPTXSlides(t *testing.T) {
+	t.Parallel()
+
+	slides := []string{
+		"ppt/slides/slide2.xml", pptxSlide(` show="0"`, "Second", "hidden body"),
+		"ppt/slides/_rels/slide2.xml.rels", `<Relationships><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" Target="../notesSlides/notesSlide1.xml"/></Relationships>`,
+		"ppt/notesSlides/notesSlide1.xml", `<p:notes xmlns:p="p" xmlns:a="a"><p:cSld><p:spTree>` +
+			`<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldImg"/></p:nvPr></p:nvSpPr></p:sp>` +
+			`<p:sp><p:nvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>Say </a:t></a:r><a:r><a:t>hello</a:t></a:r></a:p><a:p><a:r><a:t>Then bye</a:t></a:r></a:p></p:txBody></p:sp>` +
+			`<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldNum"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:fld><a:t>2</a:t></a:fld></a:p></p:txBody></p:sp>` +
+			`</p:spTree></p:cSld></p:notes>`,
+		"ppt/slides/slide3.xml", `<p:sld xmlns:p="p"><p:cSld><p:spTree/></p:cSld></p:sld>`,
+		"ppt/slides/slide10.xml", pptxSlide("", "First", "intro"),
+	}
+	presentation := []string{
+		"ppt/presentation.xml", `<p:presentation xmlns:p="p" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<p:sldIdLst><p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId4"/><p:sldId id="258" r:id="rId2"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels", `<Relationships>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide2.xml"/>` +
+			`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide10.xml"/>` +
+			`<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="/ppt/slides/slide3.xml"/>` +
+			`</Relationships>`,
+	}
+
+	data := zipBytes(t, append(slides, presentation...)...)
+	docs, err := NewPPTX(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "\nFirst\nintro", docs[0].PageContent)
+	assert.Equal(t, map[string]any{"slide": 0, "total_slides": 3, "slide_number": 1, "slide_title": "First"}, docs[0].Metadata)
+	assert.Equal(t, "\nSecond\nhidden body", docs[1].PageContent)
+	// the empty second slide is skipped, the numbers of the next slides are kept
+	assert.Equal(t, map[string]any{"slide": 2, "total_slides": 3, "slide_number": 3, "slide_title": "Second", "hidden": true}, docs[1].Metadata)
+
+	docs, err = NewPPTX(bytes.NewReader(data), int64(len(data)), PptxWithNotes(true)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "\nFirst\nintro", docs[0].PageContent)
+	assert.Equal(t, "\nSecond\nhidden body\n\nNotes:\nSay hello\nThen bye\n", docs[1].PageContent)
+
+	// without the slide list the slides are in the order of their numbers
+	data = zipBytes(t, slides...)
+	docs, err = NewPPTX(bytes.NewReader(data), int64(len(data))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Second", docs[0].Metadata["slide_title"])
+	assert.Equal(t, 0, docs[0].Metadata["slide"])
+	assert.Equal(t, 2, docs[1].Metadata["slide"])
+	assert.Equal(t, 3, docs[1].Metadata["slide_number"])
+}
@@ -24,7 +24,10 @@ func NewText(r io.Reader) Text {
 }
 
 // Load reads from the io.Reader and returns a single document with the data.
-func (l Text) Load(_ context.Context) ([]schema.Document, error) {
+func (l Text) Load(ctx context.Context) ([]schema.Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	buf := new(bytes.Buffer)
 	_, err := io.Copy(buf, l.r)
 	if err != nil {
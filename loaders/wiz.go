@@ -22,11 +22,14 @@ func NewWIZ(r io.ReaderAt, size int64) WIZ {
 }
 
 // Load reads from the io.Reader and returns a single document with the data.
-func (d WIZ) Load(_ context.Context) ([]schema.Document, error) {
+func (d WIZ) Load(ctx context.Context) ([]schema.Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	pagecontent, err := wiz.Read(d.r, d.s)
 	if err != nil {
-		return nil, err
+		return nil, NewError(ErrCorrupt, err)
 	}
 	return []schema.Document{
 		{
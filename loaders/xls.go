@@ -0,0 +1,91 @@
+package loaders
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+
+	"loader/schema"
+	"loader/textsplitter"
+	"loader/xls"
+)
+
+// XLS loads the cell values of the Excel 97-2003 binary workbooks.
+type XLS struct {
+	r io.ReaderAt
+}
+
+var _ IterLoader = XLS{}
+
+// NewXLS creates a new xls loader with an io.ReaderAt.
+func NewXLS(r io.ReaderAt) XLS {
+	return XLS{r: r}
+}
+
+// Load reads from the io.ReaderAt and returns a document for each worksheet.
+func (x XLS) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, x.LoadIter(ctx))
+}
+
+// LoadIter yields a document for each worksheet, the cells are written like the xlsx loader does.
+func (x XLS) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(schema.Document{}, err)
+			return
+		}
+		sheets, err := xls.Read(x.r)
+		if err != nil {
+			if errors.Is(err, xls.ErrVersion) {
+				yield(schema.Document{}, NewError(ErrUnsupportedType, err))
+				return
+			}
+			yield(schema.Document{}, compoundError(err))
+			return
+		}
+
+		docs := make([]schema.Document, len(sheets))
+		for i, sheet := range sheets {
+			docs[i] = schema.Document{
+				PageContent: rowsText(sheet.Rows),
+				Metadata: map[string]any{
+					"shee":         i,
+					"sheet_name":   sheet.Name,
+					"total_sheets": len(sheets),
+				},
+			}
+		}
+		yieldAll(ctx, docs, yield)
+	}
+}
+
+// rowsText returns the cells separated by tabs, one line per row, the trailing empty rows are dropped
+func rowsText(rows [][]string) string {
+	var content strings.Builder
+	emptyRows := 0
+	for _, row := range rows {
+		if len(row) == 0 {
+			emptyRows++
+			continue
+		}
+		content.WriteString(strings.Repeat("\n", emptyRows))
+		emptyRows = 0
+		for _, cell := range row {
+			content.WriteString(cell + "\t")
+		}
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+// LoadAndSplit reads text data from the io.ReaderAt and splits it into multiple
+// documents using a text splitter.
+func (x XLS) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := x.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
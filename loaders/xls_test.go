@@ -0,0 +1,121 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"loader/internal/cfbtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// biffRecord returns a record of the workbook stream
+func biffRecord(typ uint16, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+	b := binary.LittleEndian.AppendUint16(nil, typ)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(body)))
+	return append(b, body...)
+}
+
+// le returns the little endian bytes of the values
+func le(values ...any) []byte {
+	var b []byte
+	for _, v := range values {
+		b, _ = binary.Append(b, binary.LittleEndian, v)
+	}
+	return b
+}
+
+// wideChars returns the utf-16 bytes of the string
+func wideChars(s string) []byte {
+	return le(utf16.Encode([]rune(s)))
+}
+
+// workbookBytes returns a workbook with the globals and the sheet records
+func workbookBytes(version uint16, globals [][]byte, sheet [][]byte) []byte {
+	bof := biffRecord(0x0809, le(version, uint16(0x0005)), make([]byte, 12))
+	// the offset of the sheet is known once the size of the globals is
+	size := len(bof) + len(biffRecord(0x000A))
+	for _, rec := range globals {
+		size += len(rec)
+	}
+	size += len(biffRecord(0x0085, make([]byte, 6), []byte{2, 1}, wideChars("数据")))
+	stream := append([]byte{}, bof...)
+	stream = append(stream, biffRecord(0x0085, le(uint32(size), uint16(0)), []byte{2, 1}, wideChars("数据"))...)
+	for _, rec := range globals {
+		stream = append(stream, rec...)
+	}
+	stream = append(stream, biffRecord(0x000A)...)
+	stream = append(stream, biffRecord(0x0809, le(version, uint16(0x0010)), make([]byte, 12))...)
+	for _, rec := range sheet {
+		stream = append(stream, rec...)
+	}
+	stream = append(stream, biffRecord(0x000A)...)
+	return cfbtest.File(cfbtest.Stream{Name: "Workbook", Data: stream})
+}
+
+func TestXLS(t *testing.T) {
+	t.Parallel()
+
+	globals := [][]byte{
+		// a chart sheet, it has no cells
+		biffRecord(0x0085, le(uint32(0), uint16(0x0200)), []byte{5, 0}, []byte("Chart")),
+		// the cell formats: general and a built-in date format
+		biffRecord(0x00E0, le(uint16(0), uint16(0)), make([]byte, 16)),
+		biffRecord(0x00E0, le(uint16(0), uint16(14)), make([]byte, 16)),
+		// the shared strings, the last one is continued in utf-16 in the next record
+		biffRecord(0x00FC, le(uint32(3), uint32(3)),
+			le(uint16(4)), []byte{0}, []byte("Name"),
+			le(uint16(2)), []byte{1}, wideChars("张三"),
+			le(uint16(11)), []byte{0}, []byte("Hello ")),
+		biffRecord(0x003C, []byte{1}, wideChars("World")),
+	}
+	sheet := [][]byte{
+		biffRecord(0x00FD, le(uint16(0), uint16(0), uint16(0), uint32(0))),
+		biffRecord(0x00FD, le(uint16(0), uint16(1), uint16(0), uint32(2))),
+		biffRecord(0x00FD, le(uint16(0), uint16(2), uint16(0), uint32(1))),
+		biffRecord(0x0203, le(uint16(1), uint16(0), uint16(0), math.Float64bits(3.5))),
+		biffRecord(0x027E, le(uint16(1), uint16(1), uint16(0), uint32(42<<2|2))),
+		biffRecord(0x00BD, le(uint16(2), uint16(0), uint16(0), uint32(123<<2|3), uint16(1), uint32(45000<<2|2), uint16(1))),
+		biffRecord(0x0204, le(uint16(3), uint16(0), uint16(0), uint16(2)), []byte{1}, wideChars("中文")),
+		biffRecord(0x0006, le(uint16(3), uint16(1), uint16(0)), []byte{0, 0, 0, 0, 0, 0, 0xFF, 0xFF}, make([]byte, 6)),
+		biffRecord(0x0207, le(uint16(3)), []byte{0}, []byte("sum")),
+		biffRecord(0x0006, le(uint16(3), uint16(2), uint16(0)), []byte{1, 0, 1, 0, 0, 0, 0xFF, 0xFF}, make([]byte, 6)),
+		biffRecord(0x0205, le(uint16(5), uint16(0), uint16(0)), []byte{0x07, 1}),
+		// a shared formula, its string result follows the SHRFMLA record
+		biffRecord(0x0006, le(uint16(5), uint16(1), uint16(0)), []byte{0, 0, 0, 0, 0, 0, 0xFF, 0xFF}, make([]byte, 6)),
+		biffRecord(0x04BC, le(uint16(5), uint16(6), uint8(1), uint8(1)), make([]byte, 4)),
+		biffRecord(0x0207, le(uint16(6)), []byte{0}, []byte("shared")),
+	}
+	docs, err := NewXLS(bytes.NewReader(workbookBytes(0x0600, globals, sheet))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Name\tHello World\t张三\t\n3.5\t42\t\n1.23\t2023-03-15\t\n中文\tsum\tTRUE\t\n\n#DIV/0!\tshared\t\n", docs[0].PageContent)
+	assert.Equal(t, map[string]any{"shee": 0, "sheet_name": "数据", "total_sheets": 1}, docs[0].Metadata)
+
+	_, err = NewXLS(bytes.NewReader(workbookBytes(0x0600, [][]byte{biffRecord(0x002F, make([]byte, 6))}, nil))).Load(context.Background())
+	require.ErrorIs(t, err, ErrEncrypted)
+
+	_, err = NewXLS(bytes.NewReader(workbookBytes(0x0500, nil, nil))).Load(context.Background())
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestXLSCellLimits(t *testing.T) {
+	t.Parallel()
+
+	// the cells in the last column of the rows, past the columns of Excel 97 and past the cells kept
+	sheet := [][]byte{biffRecord(0x027E, le(uint16(0), uint16(16383), uint16(0), uint32(1<<2|2)))}
+	for row := 0; row < 5000; row++ {
+		sheet = append(sheet, biffRecord(0x027E, le(uint16(row), uint16(255), uint16(0), uint32(1<<2|2))))
+	}
+	docs, err := NewXLS(bytes.NewReader(workbookBytes(0x0600, nil, sheet))).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, 1<<20/256, strings.Count(docs[0].PageContent, "\t1\t\n"))
+}
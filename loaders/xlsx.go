@@ -2,69 +2,137 @@ package loaders
 
 import (
 	"context"
+	"errors"
 	"io"
+	"iter"
+	"strings"
+
 	"loader/schema"
 	"loader/textsplitter"
 
 	"github.com/xuri/excelize/v2"
 )
 
+// maxUnzipSize is the default limit of the uncompressed size of a workbook, the larger workbooks
+// are rejected before their parts are extracted
+const maxUnzipSize = 1 << 30
+
 // HTML loads parses and sanitizes html content from an io.Reader.
 type Excelx struct {
 	r    io.Reader
 	opts []excelize.Options
 }
 
-var _ Loader = HTML{}
+var _ IterLoader = Excelx{}
 
 // NewHTML creates a new html loader with an io.Reader.
 func NewExcelx(r io.Reader, opts ...excelize.Options) Excelx {
 	return Excelx{r, opts}
 }
 
-// Load reads from the io.Reader and returns a single document with the data.
-func (e Excelx) Load(_ context.Context) ([]schema.Document, error) {
-	f, err := excelize.OpenReader(e.r, e.opts...)
-	if err != nil {
-		return nil, err
-	}
-	// Get all sheet names
-	sheets := f.GetSheetList()
-
-	// Iterate over each sheet
-	docs := []schema.Document{}
-
-	numSheets := len(sheets)
-
-	for i, sheet := range sheets {
-		// fmt.Println("Reading Sheet:", sheet)
+// Load reads from the io.Reader and returns a document for each sheet.
+func (e Excelx) Load(ctx context.Context) ([]schema.Document, error) {
+	return collect(ctx, e.LoadIter(ctx))
+}
 
-		rows, err := f.GetRows(sheet)
+// LoadIter reads the sheets one by one and yields a document for each sheet,
+// the rows of the sheet are streamed so only the text of the current sheet is kept.
+func (e Excelx) LoadIter(ctx context.Context) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		opts := excelize.Options{UnzipSizeLimit: maxUnzipSize}
+		if len(e.opts) > 0 {
+			opts = e.opts[0]
+			if opts.UnzipSizeLimit == 0 {
+				opts.UnzipSizeLimit = max(maxUnzipSize, opts.UnzipXMLSizeLimit)
+			}
+		}
+		f, err := excelize.OpenReader(e.r, opts)
 		if err != nil {
-			return nil, err
-			// log.Fatalf("error getting rows from sheet %s: %s", sheet, err)
+			yield(schema.Document{}, e.openError(opts, err))
+			return
 		}
+		defer f.Close()
+
+		// Get all sheet names
+		sheets := f.GetSheetList()
+		numSheets := len(sheets)
 
-		pagecontent := ""
-		for _, row := range rows {
-			for _, cell := range row {
-				pagecontent += cell + "\t"
-				// fmt.Print(cell, "\t")
+		for i, sheet := range sheets {
+			if err := ctx.Err(); err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+			pagecontent, err := e.sheetText(ctx, f, sheet)
+			if err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+
+			doc := schema.Document{
+				PageContent: pagecontent,
+				Metadata: map[string]any{
+					"shee":         i,
+					"sheet_name":   sheet,
+					"total_sheets": numSheets,
+				},
+			}
+			if !yield(doc, nil) {
+				return
 			}
-			pagecontent += "\n"
 		}
+	}
+}
 
-		docs = append(docs, schema.Document{
-			PageContent: pagecontent,
-			Metadata: map[string]any{
-				"shee":         i,
-				"sheet_name":   sheet,
-				"total_sheets": numSheets,
-			},
-		})
+// sheetText returns the cells of the sheet separated by tabs, one line per row
+func (e Excelx) sheetText(ctx context.Context, f *excelize.File, sheet string) (string, error) {
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		return "", NewError(ErrCorrupt, err)
 	}
+	defer rows.Close()
 
-	return docs, nil
+	var content strings.Builder
+	// the empty rows are only written before a row with cells, the trailing ones are dropped like GetRows does
+	emptyRows := 0
+	for r := 0; rows.Next(); r++ {
+		if r%1000 == 0 && ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		row, err := rows.Columns()
+		if err != nil {
+			return "", NewError(ErrCorrupt, err)
+		}
+		if len(row) == 0 {
+			emptyRows++
+			continue
+		}
+		content.WriteString(strings.Repeat("\n", emptyRows))
+		emptyRows = 0
+		for _, cell := range row {
+			content.WriteString(cell + "\t")
+		}
+		content.WriteString("\n")
+	}
+	if err := rows.Error(); err != nil {
+		return "", NewError(ErrCorrupt, err)
+	}
+	return content.String(), nil
+}
+
+// openError returns the error of the workbook failed to open, the encrypted workbooks are only
+// recognised when the reader supports ReadAt
+func (e Excelx) openError(opts excelize.Options, err error) error {
+	if errors.Is(err, excelize.ErrWorkbookPassword) {
+		return NewError(ErrBadPassword, err)
+	}
+	if r, ok := e.r.(io.ReaderAt); ok && opts.Password == "" && isOLE(r) {
+		return NewError(ErrEncrypted, err)
+	}
+	// excelize has no error value for the size limit
+	if strings.HasPrefix(err.Error(), "unzip size exceeds") {
+		return NewError(ErrTooLarge, err)
+	}
+	return NewError(ErrCorrupt, err)
 }
 
 // LoadAndSplit reads text data from the io.Reader and splits it into multiple
@@ -0,0 +1,61 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExcelxRows(t *testing.T) {
+	t.Parallel()
+
+	workbook := excelize.NewFile()
+	require.NoError(t, workbook.SetCellValue("Sheet1", "A1", "name"))
+	require.NoError(t, workbook.SetCellValue("Sheet1", "B1", "age"))
+	require.NoError(t, workbook.SetCellValue("Sheet1", "A3", "John"))
+	require.NoError(t, workbook.SetCellValue("Sheet1", "B3", 25))
+	// the styled cells of the trailing rows have no value
+	style, err := workbook.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	require.NoError(t, err)
+	require.NoError(t, workbook.SetCellStyle("Sheet1", "A5", "B6", style))
+	buf, err := workbook.WriteToBuffer()
+	require.NoError(t, err)
+	data := buf.Bytes()
+
+	docs, err := NewExcelx(bytes.NewReader(data)).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "name\tage\t\n\nJohn\t25\t\n", docs[0].PageContent)
+
+	// the text is the same as the rows returned by GetRows
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	rows, err := f.GetRows("Sheet1")
+	require.NoError(t, err)
+	var content strings.Builder
+	for _, row := range rows {
+		for _, cell := range row {
+			content.WriteString(cell + "\t")
+		}
+		content.WriteString("\n")
+	}
+	assert.Equal(t, content.String(), docs[0].PageContent)
+}
+
+func TestExcelxUnzipSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	workbook := excelize.NewFile()
+	require.NoError(t, workbook.SetCellValue("Sheet1", "A1", strings.Repeat("x", 4096)))
+	buf, err := workbook.WriteToBuffer()
+	require.NoError(t, err)
+	data := buf.Bytes()
+
+	_, err = NewExcelx(bytes.NewReader(data), excelize.Options{UnzipSizeLimit: 1024, UnzipXMLSizeLimit: 1024}).Load(context.Background())
+	require.ErrorIs(t, err, ErrTooLarge)
+}
@@ -2,14 +2,17 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
+	"loader/filetype"
 	"loader/loaders"
+	"loader/schema"
 	"loader/textsplitter"
+	"loader/utils"
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	jsoniter "github.com/json-iterator/go"
@@ -36,10 +39,23 @@ func (plugin *DocumentLoader) setLogFile() {
 	plugin.Plugin.SetLogger(output, grpc.Trace)
 }
 
+// getResponse returns the data with code 200, or the error with the code and type of the error.
+// The partial data loaded before a timeout is kept in the data of the error response.
 func getResponse(v interface{}, err error) (*grpc.Response, error) {
 
 	if err != nil {
-		bytes, err := jsoniter.Marshal(map[string]interface{}{"code": 400, "message": err.Error()})
+		res := errorResponse(err)
+		switch partial := v.(type) {
+		case []schema.Document:
+			if partial != nil {
+				res["data"] = partial
+			}
+		case map[string]interface{}:
+			if partial != nil {
+				res["data"] = partial
+			}
+		}
+		bytes, err := jsoniter.Marshal(res)
 		if err != nil {
 			return nil, err
 		}
@@ -60,106 +76,388 @@ func (doc *DocumentLoader) Exec(method string, args ...interface{}) (*grpc.Respo
 	doc.Logger.Log(hclog.Trace, "args", args)
 
 	if len(args) == 0 {
-		return getResponse(nil, errors.New("missing file path"))
+		return getResponse(nil, invalidArgument("missing file path"))
+	}
+
+	method = strings.ToLower(method)
+	switch method {
+	case "split":
+		return getResponse(splitDocuments(args[0], args[1:]))
+	case "next":
+		return getResponse(cursors.next(args[0], args[1:]))
+	case "close":
+		return getResponse(cursors.close(args[0]))
 	}
 
 	path, ok := args[0].(string)
 	if !ok {
-		return getResponse(nil, errors.New("invalid file path"))
+		return getResponse(nil, invalidArgument("invalid file path"))
 	}
 
-	ftype, err := getFileType(path)
+	info, err := filetype.Detect(path)
+	if err != nil {
+		return getResponse(nil, err)
+	}
+	opts, err := parseOptions(args[1:])
 	if err != nil {
 		return getResponse(nil, err)
 	}
-	size := -1
-	if len(args) > 1 {
-		size, ok = args[1].(int)
+
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(opts.Timeout*float64(time.Second)))
+		defer cancel()
 	}
 
-	switch strings.ToLower(method) {
-	case "notation":
-		if ftype == "DIR" {
-			// Create a NotionDirectoryLoader instance
-			loader := loaders.NewNotionDirectory(path)
-			// Load documents from the test directory
-			docs, err := loader.Load()
-			if err != nil {
-				return getResponse(nil, err)
-			}
-			bytes, err := jsoniter.Marshal(docs)
+	switch method {
+	case "notion", "notation":
+		return getResponse(loadNotion(ctx, path, info, opts))
+	case "dir":
+		return getResponse(loadDirectory(ctx, path, info, opts))
+	case "text":
+		return getResponse(loadFile(ctx, path, info, opts, true))
+	case "load":
+		return getResponse(loadFile(ctx, path, info, opts, false))
+	case "open":
+		return getResponse(cursors.open(ctx, path, info, opts))
+	}
+	return getResponse(nil, invalidArgument("invalid method: %s", method))
+
+}
+
+// loadFile loads the documents of the file, and splits them when split is true.
+// The detected file type and mime type are added to the metadata of the documents.
+func loadFile(ctx context.Context, path string, info filetype.Info, opts Options, split bool) ([]schema.Document, error) {
+	warnings := &pageWarnings{}
+	f, loader, err := openFile(path, info, opts, warnings.add)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var splitter textsplitter.TextSplitter
+	if split {
+		splitter, err = newSplitter(info.Type, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var docs []schema.Document
+	if iterLoader, ok := loader.(loaders.IterLoader); ok && opts.Limit > 0 {
+		docs, err = loadPage(ctx, iterLoader, splitter, opts)
+		if err != nil {
+			return partialDocuments(ctx, docs, nil, opts, err)
+		}
+	} else {
+		docs, err = loader.Load(ctx)
+		if err != nil {
+			return partialDocuments(ctx, docs, splitter, opts, err)
+		}
+		if splitter != nil {
+			docs, err = textsplitter.SplitDocuments(splitter, docs)
 			if err != nil {
 				return nil, err
 			}
-			return getResponse(bytes, err)
-		} else {
-			return getResponse(nil, fmt.Errorf("%s is not director", path))
 		}
-	case "text":
-		f, err := os.Open(path)
+	}
+	if !loaders.HasContent(docs) {
+		return nil, loaders.NewError(loaders.ErrEmptyDocument, fmt.Errorf("no text found in %s", path))
+	}
+	for i := range docs {
+		setFileType(&docs[i], info)
+	}
+	warnings.annotate(docs)
+	return paginate(docs, opts), nil
+}
+
+// loadPage loads and splits the documents one by one, and stops once the page selected by
+// the offset and limit options is read. The documents read so far are returned on errors.
+func loadPage(ctx context.Context, loader loaders.IterLoader, splitter textsplitter.TextSplitter, opts Options) ([]schema.Document, error) {
+	seq := loader.LoadIter(ctx)
+	if splitter != nil {
+		seq = textsplitter.SplitDocumentsIter(splitter, seq)
+	}
+	docs := []schema.Document{}
+	for doc, err := range seq {
 		if err != nil {
-			return getResponse(nil, err)
+			return docs, err
+		}
+		docs = append(docs, doc)
+		if len(docs) >= opts.Offset+opts.Limit {
+			break
+		}
+	}
+	return docs, nil
+}
+
+// openFile opens the file and creates its loader, the file must be closed by the caller.
+// The pages failed to read are passed to onPageError in the tolerant mode.
+func openFile(path string, info filetype.Info, opts Options, onPageError func(page int, err error)) (*os.File, loaders.Loader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	finfo, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if opts.MaxFileSize > 0 && finfo.Size() > opts.MaxFileSize {
+		f.Close()
+		return nil, nil, loaders.NewError(loaders.ErrTooLarge, fmt.Errorf("file size %d exceeds the limit %d", finfo.Size(), opts.MaxFileSize))
+	}
+	loader, err := newLoader(info, f, finfo.Size(), opts, onPageError)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if loader == nil {
+		f.Close()
+		return nil, nil, loaders.NewError(loaders.ErrUnsupportedType, fmt.Errorf("%s not support:%s", info.Type, path))
+	}
+	return f, loader, nil
+}
+
+// setFileType adds the detected file type and mime type to the metadata of the document
+func setFileType(doc *schema.Document, info filetype.Info) {
+	if doc.Metadata == nil {
+		doc.Metadata = map[string]any{}
+	}
+	doc.Metadata["file_type"] = info.Type
+	doc.Metadata["mime_type"] = info.MIME
+}
+
+// pageWarnings collects the pages skipped in the tolerant mode
+type pageWarnings struct {
+	pages    []int
+	messages []string
+}
+
+// add records the page failed to read
+func (w *pageWarnings) add(page int, err error) {
+	w.pages = append(w.pages, page)
+	w.messages = append(w.messages, fmt.Sprintf("page %d: %s", page, err.Error()))
+}
+
+// take returns the warnings recorded since the previous call and clears the failed pages
+func (w *pageWarnings) take() []string {
+	messages := w.messages
+	*w = pageWarnings{}
+	return messages
+}
+
+// annotate adds the failed pages and their warnings to the metadata of the documents
+func (w *pageWarnings) annotate(docs []schema.Document) {
+	if len(w.pages) == 0 {
+		return
+	}
+	for i := range docs {
+		if docs[i].Metadata == nil {
+			docs[i].Metadata = map[string]any{}
 		}
-		defer f.Close()
-		finfo, err := f.Stat()
+		docs[i].Metadata["failed_pages"] = w.pages
+		docs[i].Metadata["warnings"] = w.messages
+	}
+}
+
+// paginate returns the documents in the page selected by the offset and limit options
+func paginate(docs []schema.Document, opts Options) []schema.Document {
+	if opts.Offset >= len(docs) {
+		return []schema.Document{}
+	}
+	docs = docs[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(docs) {
+		docs = docs[:opts.Limit]
+	}
+	return docs
+}
+
+// partialDocuments returns the documents loaded before the context is done along with the error,
+// they are only kept when the partial option is set.
+func partialDocuments(ctx context.Context, docs []schema.Document, splitter textsplitter.TextSplitter, opts Options, err error) ([]schema.Document, error) {
+	if ctx.Err() == nil || !opts.Partial || len(docs) == 0 {
+		return nil, err
+	}
+	if splitter != nil {
+		split, splitErr := textsplitter.SplitDocuments(splitter, docs)
+		if splitErr != nil {
+			return nil, err
+		}
+		docs = split
+	}
+	return docs, err
+}
+
+// loadNotion loads the pages of the Notion export directory, and splits them with the markdown splitter by default
+func loadNotion(ctx context.Context, path string, info filetype.Info, opts Options) ([]schema.Document, error) {
+	if info.Type != filetype.DIR {
+		return nil, invalidArgument("%s is not a directory", path)
+	}
+	loader := loaders.NewNotionDirectory(path, opts.Encoding)
+	splitter, err := newSplitter(filetype.MD, opts)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := loader.Load(ctx)
+	if err != nil {
+		return partialDocuments(ctx, docs, splitter, opts, err)
+	}
+	if splitter != nil {
+		docs, err = textsplitter.SplitDocuments(splitter, docs)
 		if err != nil {
-			return getResponse(nil, err)
-		}
-		var loader loaders.Loader
-		var splitter textsplitter.TextSplitter
-		switch ftype {
-		case "WIZ":
-			loader = loaders.NewWIZ(f, finfo.Size())
-			splitter = textsplitter.NewRecursiveCharacter(textsplitter.WithChunkSize(size))
-		case "DOCX":
-			loader = loaders.NewDocx(f, finfo.Size())
-			splitter = textsplitter.NewRecursiveCharacter(textsplitter.WithChunkSize(size))
-		case "PPTX":
-			loader = loaders.NewPPTX(f, finfo.Size())
-			splitter = textsplitter.NewRecursiveCharacter(textsplitter.WithChunkSize(size))
-		case "XLSX":
-			if len(args) > 1 {
-				password, ok := args[1].(string)
-				if ok {
-					loader = loaders.NewExcelx(f, excelize.Options{Password: password})
-				}
-			} else {
-				loader = loaders.NewExcelx(f)
-			}
+			return nil, err
+		}
+	}
+	return paginate(docs, opts), nil
+}
+
+// loadDirectory loads the files of the directory tree, each file is split with the splitter of its type.
+// The files failed to load are reported in the errors instead of aborting the whole load.
+func loadDirectory(ctx context.Context, path string, info filetype.Info, opts Options) (map[string]interface{}, error) {
+	if info.Type != filetype.DIR {
+		return nil, invalidArgument("%s is not a directory", path)
+	}
 
-			splitter = textsplitter.NewRecursiveCharacter(textsplitter.WithChunkSize(size))
-		case "PDF":
-			if len(args) > 1 {
-				password, ok := args[1].(string)
-				if ok {
-					loader = loaders.NewPDF(f, finfo.Size(), loaders.PdfWithPassword(password))
-				}
-			} else {
-				loader = loaders.NewPDF(f, finfo.Size())
+	failures := []map[string]interface{}{}
+	loader := loaders.NewDirectory(path,
+		func(info filetype.Info, f *os.File, size int64) (loaders.Loader, error) {
+			return newLoader(info, f, size, opts, func(page int, err error) {
+				failure := errorResponse(err)
+				failure["source"] = f.Name()
+				failure["page"] = page
+				failures = append(failures, failure)
+			})
+		},
+		loaders.DirWithInclude(opts.Include...),
+		loaders.DirWithExclude(opts.Exclude...),
+		loaders.DirWithMaxDepth(opts.MaxDepth),
+		loaders.DirWithMaxFileSize(opts.MaxFileSize),
+		loaders.DirWithErrorHandler(func(path string, err error) {
+			failure := errorResponse(err)
+			failure["source"] = path
+			failures = append(failures, failure)
+		}),
+	)
+	docs, err := loader.Load(ctx)
+	if err != nil && (ctx.Err() == nil || !opts.Partial) {
+		return nil, err
+	}
+	loadErr := err
+
+	splitters := map[string]textsplitter.TextSplitter{}
+	chunks := []schema.Document{}
+	for _, doc := range docs {
+		ftype, _ := doc.Metadata["file_type"].(string)
+		splitter, ok := splitters[ftype]
+		if !ok {
+			splitter, err = newSplitter(ftype, opts)
+			if err != nil {
+				return nil, err
 			}
-			splitter = textsplitter.NewRecursiveCharacter(textsplitter.WithChunkSize(size))
-		case "MD":
-			loader = loaders.NewText(f)
-			splitter = textsplitter.NewMarkdownTextSplitter(textsplitter.WithChunkSize(size), textsplitter.WithCodeBlocks(true))
-		case "HTML":
-			loader = loaders.NewHTML(f)
-			splitter = textsplitter.NewRecursiveCharacter(textsplitter.WithChunkSize(size))
-		case "CSV":
-			loader = loaders.NewCSV(f)
-			splitter = textsplitter.NewRecursiveCharacter(textsplitter.WithChunkSize(size))
-		case "TEXT":
-			loader = loaders.NewText(f)
-			splitter = textsplitter.NewRecursiveCharacter(textsplitter.WithChunkSize(size))
+			splitters[ftype] = splitter
+		}
+		if splitter == nil {
+			chunks = append(chunks, doc)
+			continue
 		}
-		if loader == nil {
-			return getResponse(nil, fmt.Errorf("%s not support:%s", ftype, path))
+		split, err := textsplitter.SplitDocuments(splitter, []schema.Document{doc})
+		if err != nil {
+			return nil, err
 		}
-		docs, err := loader.LoadAndSplit(context.Background(), splitter)
-		return getResponse(docs, err)
+		chunks = append(chunks, split...)
+	}
+	return map[string]interface{}{"documents": chunks, "errors": failures}, loadErr
+}
 
+// newLoader creates the loader of the file type with the options,
+// the pages failed to read are passed to onPageError when the tolerant option is set.
+func newLoader(info filetype.Info, f *os.File, size int64, opts Options, onPageError func(page int, err error)) (loaders.Loader, error) {
+	switch info.Type {
+	case filetype.WIZ:
+		return loaders.NewWIZ(f, size), nil
+	case filetype.DOCX:
+		docxOpts := []loaders.DocxOptions{
+			loaders.DocxWithMarkdown(opts.Markdown),
+			loaders.DocxWithTables(opts.TableFormat),
+			loaders.DocxWithHeaders(opts.Headers),
+			loaders.DocxWithNotes(opts.Notes),
+			loaders.DocxWithComments(opts.Comments),
+			loaders.DocxWithRevisions(opts.Revisions),
+			loaders.DocxWithPages(opts.Paging),
+			loaders.DocxWithEmbedded(opts.Embedded),
+		}
+		if ocr := ocrProvider(opts); ocr != nil {
+			docxOpts = append(docxOpts, loaders.DocxWithOCR(ocr))
+		}
+		return loaders.NewDocx(f, size, docxOpts...), nil
+	case filetype.PPTX:
+		pptxOpts := []loaders.PPTXOptions{loaders.PptxWithNotes(opts.SpeakerNotes)}
+		if ocr := ocrProvider(opts); ocr != nil {
+			pptxOpts = append(pptxOpts, loaders.PptxWithOCR(ocr))
+		}
+		return loaders.NewPPTX(f, size, pptxOpts...), nil
+	case filetype.XLSX:
+		return loaders.NewExcelx(f, excelize.Options{Password: opts.Password}), nil
+	case filetype.DOC:
+		return loaders.NewDoc(f), nil
+	case filetype.XLS:
+		return loaders.NewXLS(f), nil
+	case filetype.PPT:
+		return loaders.NewPPT(f), nil
+	case filetype.ODT:
+		return loaders.NewODT(f, size, loaders.OdtWithMarkdown(opts.Markdown), loaders.OdtWithTables(opts.TableFormat)), nil
+	case filetype.ODS:
+		return loaders.NewODS(f, size), nil
+	case filetype.ODP:
+		return loaders.NewODP(f, size), nil
+	case filetype.PDF:
+		pdfOpts := []loaders.PDFOptions{
+			loaders.PdfWithPassword(opts.Password),
+			loaders.PdfWithLayout(opts.Layout),
+			loaders.PdfWithTables(opts.Tables),
+			loaders.PdfWithAnnotations(opts.Annotations),
+			loaders.PdfWithPages(opts.Pages),
+			loaders.PdfWithImagePlaceholders(opts.Placeholders),
+		}
+		if opts.Tolerant {
+			pdfOpts = append(pdfOpts, loaders.PdfWithPageErrorHandler(onPageError))
+		}
+		if ocr := ocrProvider(opts); ocr != nil {
+			pdfOpts = append(pdfOpts, loaders.PdfWithOCR(ocr))
+		}
+		return loaders.NewPDF(f, size, pdfOpts...), nil
+	case filetype.HTML:
+		return loaders.NewHTML(f), nil
+	case filetype.MD, filetype.CSV, filetype.TEXT:
+		encoding := opts.Encoding
+		if encoding == "" {
+			// the encoding of the byte order mark
+			encoding = info.Encoding
+		}
+		r, err := utils.NewEncodingReader(f, encoding)
+		if err != nil {
+			return nil, err
+		}
+		if info.Type == filetype.CSV {
+			return loaders.NewCSV(r, opts.Columns...), nil
+		}
+		return loaders.NewText(r), nil
 	}
-	return getResponse(nil, errors.New("invalid method"))
+	return nil, nil
+}
 
+// ocrProvider returns the OCR provider when the ocr option is set. The command is configured by the
+// DOCLOADER_OCR_COMMAND environment variable, e.g. "tesseract {image} stdout -l eng", where {image} is
+// replaced by the path of the image file, tesseract is used by default.
+func ocrProvider(opts Options) loaders.OCRProvider {
+	if !opts.OCR {
+		return nil
+	}
+	if command := strings.Fields(os.Getenv("DOCLOADER_OCR_COMMAND")); len(command) > 0 {
+		return loaders.NewCommandOCR(command[0], command[1:]...)
+	}
+	return loaders.DefaultOCR(opts.OCRLanguages)
 }
 
 func main() {
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"io"
 	"os"
 	"reflect"
 	"testing"
 
+	"loader/loaders"
+	"loader/schema"
+
 	"github.com/yaoapp/kun/grpc"
 )
 
@@ -51,3 +56,56 @@ func TestDocumentLoader_Exec(t *testing.T) {
 		})
 	}
 }
+
+func TestPartialDocuments(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	loaded := []schema.Document{{PageContent: "page 1"}}
+
+	docs, err := partialDocuments(ctx, loaded, nil, Options{}, ctx.Err())
+	if !errors.Is(err, context.Canceled) || docs != nil {
+		t.Errorf("partialDocuments() = %v, %v, want nil documents", docs, err)
+	}
+
+	docs, err = partialDocuments(ctx, loaded, nil, Options{Partial: true}, ctx.Err())
+	if !errors.Is(err, context.Canceled) || len(docs) != 1 {
+		t.Errorf("partialDocuments() = %v, %v, want the loaded documents", docs, err)
+	}
+
+	docs, err = partialDocuments(context.Background(), loaded, nil, Options{Partial: true}, io.ErrUnexpectedEOF)
+	if docs != nil || err != io.ErrUnexpectedEOF {
+		t.Errorf("partialDocuments() = %v, %v, want nil documents", docs, err)
+	}
+}
+
+func TestPageWarnings(t *testing.T) {
+	docs := []schema.Document{{PageContent: "page 1"}, {PageContent: "page 3", Metadata: map[string]any{"page": 3}}}
+
+	warnings := &pageWarnings{}
+	warnings.annotate(docs)
+	if docs[0].Metadata != nil {
+		t.Errorf("annotate() without failed pages set metadata %v", docs[0].Metadata)
+	}
+
+	warnings.add(2, loaders.ErrCorrupt)
+	warnings.annotate(docs)
+	for _, doc := range docs {
+		pages, _ := doc.Metadata["failed_pages"].([]int)
+		messages, _ := doc.Metadata["warnings"].([]string)
+		if len(pages) != 1 || pages[0] != 2 || len(messages) != 1 || messages[0] != "page 2: document is corrupt" {
+			t.Errorf("annotate() metadata = %v, want the failed page 2", doc.Metadata)
+		}
+	}
+
+	// the warnings of the cursor batches are taken one batch at a time, a page failing again is reported again
+	if messages := warnings.take(); len(messages) != 1 {
+		t.Errorf("take() = %v, want the warning of page 2", messages)
+	}
+	if messages := warnings.take(); len(messages) != 0 || len(warnings.pages) != 0 {
+		t.Errorf("take() = %v after the warnings were taken, want none", messages)
+	}
+	warnings.add(2, loaders.ErrCorrupt)
+	if messages := warnings.take(); len(messages) != 1 || messages[0] != "page 2: document is corrupt" {
+		t.Errorf("take() = %v, want the warning of page 2 again", messages)
+	}
+}
@@ -0,0 +1,612 @@
+// Package odf reads the content of the OpenDocument text documents, spreadsheets and presentations.
+package odf
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The namespaces of the elements read
+const (
+	nsOffice       = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	nsText         = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	nsTable        = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	nsDraw         = "urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"
+	nsStyle        = "urn:oasis:names:tc:opendocument:xmlns:style:1.0"
+	nsPresentation = "urn:oasis:names:tc:opendocument:xmlns:presentation:1.0"
+)
+
+// The limits of the repeated cells, the spreadsheets repeat the empty cells to the end of the sheet.
+// maxCells bounds the cells expanded in a table, the empty rows counting as one cell, so a few repeated
+// rows and cells cannot expand to the whole grid of the sheet.
+const (
+	maxColumns = 1 << 14
+	maxCells   = 1 << 20
+)
+
+// ErrEncrypted is returned when the content of the document is encrypted
+var ErrEncrypted = errors.New("document is encrypted")
+
+// Block is a paragraph or a table of the document
+type Block struct {
+	Paragraph *Paragraph
+	Table     *Table
+}
+
+// Paragraph is a paragraph, a heading or a list item
+type Paragraph struct {
+	Text string
+	// Heading is the outline level of the heading, 0 when the paragraph is not a heading
+	Heading int
+	// List is the nesting level of the list item, 0 when the paragraph is not in a list
+	List int
+}
+
+// Table is a table of a text document or a sheet of a spreadsheet
+type Table struct {
+	Name string
+	// Rows are the cells of the table, the empty cells and rows repeated to the end of the table are dropped
+	Rows [][]Cell
+}
+
+// Cell is a cell of a table
+type Cell struct {
+	Text string
+	// Covered is true for the cells covered by a cell spanning several rows or columns
+	Covered bool
+	// ColumnSpan and RowSpan are the numbers of the columns and rows spanned, 1 for the cells not merged
+	ColumnSpan int
+	RowSpan    int
+}
+
+// Slide is a page of a presentation
+type Slide struct {
+	Name string
+	// Number is the 1-based position of the slide in the presentation
+	Number int
+	// Title is the text of the title placeholder of the slide
+	Title  string
+	Hidden bool
+	Blocks []Block
+}
+
+// Grid returns the texts of the cells, the covered cells have the text of the cell covering them
+// and spanned tells the cells covered by the cell on their left. At most maxCells covered cells are
+// looked up from the spanning cells.
+func (t Table) Grid() (grid [][]string, spanned [][]bool) {
+	grid = make([][]string, len(t.Rows))
+	spanned = make([][]bool, len(t.Rows))
+	type origin struct {
+		text string
+		left bool
+	}
+	covering := map[[2]int]origin{}
+	budget := maxCells
+	for r, row := range t.Rows {
+		grid[r] = make([]string, len(row))
+		spanned[r] = make([]bool, len(row))
+		for c, cell := range row {
+			if cell.Covered {
+				o := covering[[2]int{r, c}]
+				grid[r][c], spanned[r][c] = o.text, o.left
+				continue
+			}
+			grid[r][c] = cell.Text
+			// only the cells of the table can be covered
+			for dr := 0; dr < min(cell.RowSpan, len(t.Rows)-r) && budget > 0; dr++ {
+				for dc := 0; dc < min(cell.ColumnSpan, len(t.Rows[r+dr])-c) && budget > 0; dc++ {
+					if dr > 0 || dc > 0 {
+						covering[[2]int{r + dr, c + dc}] = origin{text: cell.Text, left: dc > 0}
+						budget--
+					}
+				}
+			}
+		}
+	}
+	return grid, spanned
+}
+
+// Text returns the texts of the paragraphs of the blocks on separate lines, the cells of
+// the tables are separated by " | "
+func Text(blocks []Block) string {
+	lines := []string{}
+	for _, block := range blocks {
+		if block.Paragraph != nil {
+			if text := strings.TrimSpace(block.Paragraph.Text); text != "" {
+				lines = append(lines, text)
+			}
+			continue
+		}
+		for _, row := range block.Table.Rows {
+			texts := []string{}
+			for _, cell := range row {
+				if text := strings.Join(strings.Fields(cell.Text), " "); text != "" && !cell.Covered {
+					texts = append(texts, text)
+				}
+			}
+			if len(texts) > 0 {
+				lines = append(lines, strings.Join(texts, " | "))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ReadText returns the blocks of the body of a text document
+func ReadText(r io.ReaderAt, size int64) ([]Block, error) {
+	var blocks []Block
+	err := readContent(r, size, func(d *decoder, start xml.StartElement) error {
+		if start.Name.Space != nsOffice || start.Name.Local != "text" {
+			return d.Skip()
+		}
+		var err error
+		blocks, err = d.blocks(0)
+		return err
+	})
+	return blocks, err
+}
+
+// ReadSheets returns the sheets of a spreadsheet
+func ReadSheets(r io.ReaderAt, size int64) ([]Table, error) {
+	var sheets []Table
+	err := readContent(r, size, func(d *decoder, start xml.StartElement) error {
+		if start.Name.Space != nsOffice || start.Name.Local != "spreadsheet" {
+			return d.Skip()
+		}
+		blocks, err := d.blocks(0)
+		for _, block := range blocks {
+			if block.Table != nil {
+				sheets = append(sheets, *block.Table)
+			}
+		}
+		return err
+	})
+	return sheets, err
+}
+
+// ReadSlides returns the pages of a presentation, the notes of the pages are left out
+func ReadSlides(r io.ReaderAt, size int64) ([]Slide, error) {
+	var slides []Slide
+	err := readContent(r, size, func(d *decoder, start xml.StartElement) error {
+		if start.Name.Space != nsOffice || start.Name.Local != "presentation" {
+			return d.Skip()
+		}
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Space != nsDraw || t.Name.Local != "page" {
+					if err := d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				slide, err := d.page(t)
+				if err != nil {
+					return err
+				}
+				slide.Number = len(slides) + 1
+				slides = append(slides, slide)
+			case xml.EndElement:
+				return nil
+			}
+		}
+	})
+	return slides, err
+}
+
+// readContent decodes content.xml of the document, body is called with each child of office:body
+// after the automatic styles are read
+func readContent(r io.ReaderAt, size int64, body func(d *decoder, start xml.StartElement) error) error {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	var content *zip.File
+	for _, file := range zipReader.File {
+		switch file.Name {
+		case "content.xml":
+			content = file
+		case "META-INF/manifest.xml":
+			if encrypted(file) {
+				return ErrEncrypted
+			}
+		}
+	}
+	if content == nil {
+		return fmt.Errorf("content.xml not found")
+	}
+
+	rc, err := content.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	d := &decoder{Decoder: xml.NewDecoder(rc)}
+	inBody := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == nsOffice && t.Name.Local == "body":
+				inBody = true
+			case t.Name.Space == nsOffice && t.Name.Local == "automatic-styles":
+				if d.hidden, err = d.hiddenStyles(); err != nil {
+					return err
+				}
+			case inBody:
+				if err := body(d, t); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space == nsOffice && t.Name.Local == "body" {
+				inBody = false
+			}
+		}
+	}
+}
+
+// encrypted checks the encryption data of the files in the manifest
+func encrypted(file *zip.File) bool {
+	rc, err := file.Open()
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, 1<<20))
+	return err == nil && bytes.Contains(data, []byte("encryption-data"))
+}
+
+// spaces are the white spaces collapsed in the text of the paragraphs
+var spaces = regexp.MustCompile(`[ \t\r\n]+`)
+
+// decoder reads the elements of the content
+type decoder struct {
+	*xml.Decoder
+	// hidden are the names of the styles of the hidden slides
+	hidden map[string]bool
+}
+
+// hiddenStyles reads the automatic styles and returns the names of the styles hiding the slides
+func (d *decoder) hiddenStyles() (map[string]bool, error) {
+	hidden := map[string]bool{}
+	name := ""
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case t.Name.Space == nsStyle && t.Name.Local == "style":
+				name = attr(t, nsStyle, "name")
+			case t.Name.Space == nsStyle && t.Name.Local == "drawing-page-properties":
+				if attr(t, nsPresentation, "visibility") == "hidden" {
+					hidden[name] = true
+				}
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				return hidden, nil
+			}
+			depth--
+		}
+	}
+}
+
+// page reads the blocks of a slide, its title is the text of the frame of the title placeholder
+func (d *decoder) page(start xml.StartElement) (Slide, error) {
+	slide := Slide{Name: attr(start, nsDraw, "name"), Hidden: d.hidden[attr(start, nsDraw, "style-name")]}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return Slide{}, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skipped[t.Name] {
+				if err := d.Skip(); err != nil {
+					return Slide{}, err
+				}
+				continue
+			}
+			blocks, err := d.blocks(0)
+			if err != nil {
+				return Slide{}, err
+			}
+			if t.Name.Space == nsDraw && t.Name.Local == "frame" && attr(t, nsPresentation, "class") == "title" && slide.Title == "" {
+				slide.Title = strings.Join(strings.Fields(Text(blocks)), " ")
+			}
+			slide.Blocks = append(slide.Blocks, blocks...)
+		case xml.EndElement:
+			return slide, nil
+		}
+	}
+}
+
+// skipped are the elements without the text of the document: the tracked changes, the comments,
+// the notes, the tables of contents, the forms and the notes of the slides
+var skipped = map[xml.Name]bool{
+	{Space: nsText, Local: "tracked-changes"}:       true,
+	{Space: nsText, Local: "sequence-decls"}:        true,
+	{Space: nsText, Local: "variable-decls"}:        true,
+	{Space: nsText, Local: "user-field-decls"}:      true,
+	{Space: nsText, Local: "table-of-content"}:      true,
+	{Space: nsText, Local: "note"}:                  true,
+	{Space: nsOffice, Local: "annotation"}:          true,
+	{Space: nsOffice, Local: "forms"}:               true,
+	{Space: nsTable, Local: "table-columns"}:        true,
+	{Space: nsTable, Local: "table-column"}:         true,
+	{Space: nsTable, Local: "table-header-columns"}: true,
+	{Space: nsTable, Local: "named-expressions"}:    true,
+	{Space: nsPresentation, Local: "notes"}:         true,
+}
+
+// blocks reads the paragraphs and the tables until the end of the current element,
+// the other elements are read for the blocks they contain
+func (d *decoder) blocks(list int) ([]Block, error) {
+	var blocks []Block
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var more []Block
+			switch {
+			case skipped[t.Name]:
+				err = d.Skip()
+			case t.Name.Space == nsText && (t.Name.Local == "p" || t.Name.Local == "h"):
+				more, err = d.paragraph(t, list)
+			case t.Name.Space == nsText && t.Name.Local == "list":
+				more, err = d.blocks(list + 1)
+			case t.Name.Space == nsTable && t.Name.Local == "table":
+				var table Table
+				table, err = d.table(t, maxCells)
+				more = []Block{{Table: &table}}
+			default:
+				more, err = d.blocks(list)
+			}
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, more...)
+		case xml.EndElement:
+			return blocks, nil
+		}
+	}
+}
+
+// paragraph reads a paragraph or a heading, the blocks of the frames anchored in the paragraph follow it
+func (d *decoder) paragraph(start xml.StartElement, list int) ([]Block, error) {
+	p := &Paragraph{List: list}
+	if start.Name.Local == "h" {
+		p.Heading = 1
+		if level, err := strconv.Atoi(attr(start, nsText, "outline-level")); err == nil && level > 0 {
+			p.Heading = level
+		}
+	}
+	var sb strings.Builder
+	var frames []Block
+	depth := 0
+	for depth >= 0 {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			// the white spaces of the text are collapsed, the spaces kept are written as text:s
+			sb.WriteString(spaces.ReplaceAllString(string(t), " "))
+		case xml.StartElement:
+			switch {
+			case skipped[t.Name]:
+				err = d.Skip()
+			case t.Name.Space == nsText && t.Name.Local == "s":
+				count, convErr := strconv.Atoi(attr(t, nsText, "c"))
+				if convErr != nil || count < 1 {
+					count = 1
+				}
+				sb.WriteString(strings.Repeat(" ", min(count, 1000)))
+				err = d.Skip()
+			case t.Name.Space == nsText && t.Name.Local == "tab":
+				sb.WriteString("\t")
+				err = d.Skip()
+			case t.Name.Space == nsText && t.Name.Local == "line-break":
+				sb.WriteString("\n")
+				err = d.Skip()
+			case t.Name.Space == nsDraw && t.Name.Local == "frame":
+				var more []Block
+				more, err = d.blocks(0)
+				frames = append(frames, more...)
+			default:
+				depth++
+			}
+			if err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	p.Text = sb.String()
+	return append([]Block{{Paragraph: p}}, frames...), nil
+}
+
+// table reads the rows of a table, the repeated rows and cells are expanded except the empty ones
+// at the end of the rows and of the table. At most budget cells are expanded, the rows after are dropped.
+func (d *decoder) table(start xml.StartElement, budget int) (Table, error) {
+	table := Table{Name: attr(start, nsTable, "name")}
+	emptyRows := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return Table{}, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case skipped[t.Name]:
+				err = d.Skip()
+			case t.Name.Space == nsTable && t.Name.Local == "table-row":
+				var row []Cell
+				row, err = d.row(budget)
+				if err != nil {
+					return Table{}, err
+				}
+				repeat := repeated(t, "number-rows-repeated")
+				if len(row) == 0 {
+					emptyRows = min(emptyRows+repeat, maxCells)
+					continue
+				}
+				for i := 0; i < emptyRows+repeat; i++ {
+					next, cells := row, len(row)
+					if i < emptyRows {
+						next, cells = nil, 1
+					}
+					if cells > budget {
+						break
+					}
+					table.Rows = append(table.Rows, next)
+					budget -= cells
+				}
+				emptyRows = 0
+			default:
+				// the header rows and the groups of rows are read as the rows they contain
+				var inner Table
+				inner, err = d.table(t, budget)
+				for _, row := range inner.Rows {
+					budget -= max(len(row), 1)
+				}
+				table.Rows = append(table.Rows, inner.Rows...)
+			}
+			if err != nil {
+				return Table{}, err
+			}
+		case xml.EndElement:
+			return table, nil
+		}
+	}
+}
+
+// row reads the cells of a row, up to budget cells are expanded
+func (d *decoder) row(budget int) ([]Cell, error) {
+	var cells []Cell
+	emptyCells := 0
+	limit := min(budget, maxColumns)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != nsTable || (t.Name.Local != "table-cell" && t.Name.Local != "covered-table-cell") {
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			blocks, err := d.blocks(0)
+			if err != nil {
+				return nil, err
+			}
+			cell := Cell{
+				Text:       cellText(blocks),
+				Covered:    t.Name.Local == "covered-table-cell",
+				ColumnSpan: repeated(t, "number-columns-spanned"),
+				RowSpan:    repeated(t, "number-rows-spanned"),
+			}
+			if cell.Text == "" {
+				// the value of the cells saved without their text
+				cell.Text = value(t)
+			}
+			repeat := repeated(t, "number-columns-repeated")
+			if cell.Text == "" && !cell.Covered && cell.ColumnSpan == 1 && cell.RowSpan == 1 {
+				emptyCells = min(emptyCells+repeat, maxColumns)
+				continue
+			}
+			for i := 0; i < emptyCells+repeat && len(cells) < limit; i++ {
+				if i < emptyCells {
+					cells = append(cells, Cell{ColumnSpan: 1, RowSpan: 1})
+				} else {
+					cells = append(cells, cell)
+				}
+			}
+			emptyCells = 0
+		case xml.EndElement:
+			return cells, nil
+		}
+	}
+}
+
+// cellText returns the text of the blocks of a cell on a line, the rows of the nested tables are separated by semicolons
+func cellText(blocks []Block) string {
+	parts := []string{}
+	for _, block := range blocks {
+		text := ""
+		if block.Paragraph != nil {
+			text = block.Paragraph.Text
+		} else {
+			text = strings.ReplaceAll(Text([]Block{block}), "\n", "; ")
+		}
+		if text = strings.Join(strings.Fields(text), " "); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// valueAttrs are the attributes of the values of the cells by their types
+var valueAttrs = []string{"value", "date-value", "time-value", "boolean-value", "string-value"}
+
+// value returns the value of a cell from its attributes
+func value(start xml.StartElement) string {
+	for _, name := range valueAttrs {
+		if v := attr(start, nsOffice, name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// repeated returns the positive count of the table attribute, 1 by default and at most maxCells
+func repeated(start xml.StartElement, name string) int {
+	n, err := strconv.Atoi(attr(start, nsTable, name))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return min(n, maxCells)
+}
+
+// attr returns the value of the attribute in the namespace
+func attr(start xml.StartElement, space, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Space == space && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
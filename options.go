@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"loader/loaders"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// strictJSON rejects unknown option names so that typos are reported instead of ignored
+var strictJSON = jsoniter.Config{
+	EscapeHTML:             true,
+	DisallowUnknownFields:  true,
+	ValidateJsonRawMessage: true,
+}.Froze()
+
+// Options are the optional parameters of the plugin methods.
+//
+// They are passed after the file path either as a map (from yao scripts) or as a JSON string:
+//
+//	Process("plugins.docloader.text", file, { chunk_size: 500, password: "xxx" })
+//
+// For compatibility the old positional arguments are still accepted,
+// a number is used as the chunk size and a string as the password.
+type Options struct {
+	ChunkSize    int      `json:"chunk_size,omitempty"`
+	ChunkOverlap *int     `json:"chunk_overlap,omitempty"`
+	Splitter     string   `json:"splitter,omitempty"`
+	Separators   []string `json:"separators,omitempty"`
+
+	// KeepSeparator keeps the separators in the chunks of the recursive splitter
+	KeepSeparator *bool `json:"keep_separator,omitempty"`
+	// ModelName and EncodingName select the tiktoken encoding of the token splitter
+	ModelName    string `json:"model_name,omitempty"`
+	EncodingName string `json:"encoding_name,omitempty"`
+	// HeadingHierarchy, JoinTableRows and CodeBlocks are options of the markdown splitter
+	HeadingHierarchy *bool `json:"heading_hierarchy,omitempty"`
+	JoinTableRows    bool  `json:"join_table_rows,omitempty"`
+	CodeBlocks       *bool `json:"code_blocks,omitempty"`
+
+	Password string   `json:"password,omitempty"`
+	Columns  []string `json:"columns,omitempty"`
+	Encoding string   `json:"encoding,omitempty"`
+
+	// Layout extracts the text of the pdf pages in reading order from the positions of the characters
+	Layout bool `json:"layout,omitempty"`
+	// Tables converts the tables of the pdf pages into markdown, "inline" or "separate"
+	Tables string `json:"tables,omitempty"`
+	// Annotations extracts the form fields, comments and links of the pdf pages, "inline" or "separate"
+	Annotations string `json:"annotations,omitempty"`
+	// Markdown converts the docx and odt files into markdown, the headings and the lists are kept
+	Markdown bool `json:"markdown,omitempty"`
+	// TableFormat is the format of the tables of the docx and odt files, "markdown" or "rows"
+	TableFormat string `json:"table_format,omitempty"`
+	// Headers and Comments return the headers and footers and the comments of the docx files as documents,
+	// Notes writes the footnotes and the endnotes at their references, "inline", or after the body, "append"
+	Headers  bool   `json:"headers,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+	Comments bool   `json:"comments,omitempty"`
+	// Revisions sets how the tracked changes of the docx files are read, "accept", "reject" or "show"
+	Revisions string `json:"revisions,omitempty"`
+	// Paging returns a document for each page of the docx files, the pages are approximate
+	Paging bool `json:"paging,omitempty"`
+	// Embedded loads the xlsx workbooks embedded in the docx files
+	Embedded bool `json:"embedded,omitempty"`
+	// SpeakerNotes appends the speaker notes of the pptx slides after their text
+	SpeakerNotes bool `json:"speaker_notes,omitempty"`
+	// Pages selects the pdf pages to load, e.g. "1-5,10"
+	Pages string `json:"pages,omitempty"`
+	// Tolerant skips the pdf pages failed to read, they are reported in the failed_pages and warnings metadata
+	Tolerant bool `json:"tolerant,omitempty"`
+	// Placeholders returns an empty document for each image only pdf page, like the scanned pages
+	Placeholders bool `json:"placeholders,omitempty"`
+	// OCR recognizes the text of the image only pdf pages and the pictures of the docx and pptx files,
+	// OCRLanguages are the tesseract languages, e.g. "chi_sim+eng"
+	OCR          bool   `json:"ocr,omitempty"`
+	OCRLanguages string `json:"ocr_languages,omitempty"`
+
+	// Include, Exclude and MaxDepth are options of the dir method, MaxFileSize also applies to the single files
+	Include     []string `json:"include,omitempty"`
+	Exclude     []string `json:"exclude,omitempty"`
+	MaxDepth    int      `json:"max_depth,omitempty"`
+	MaxFileSize int64    `json:"max_file_size,omitempty"`
+
+	// Timeout is the max seconds of the method, zero means no limit.
+	// When Partial is true the documents loaded before the timeout are returned along with the error.
+	Timeout float64 `json:"timeout,omitempty"`
+	Partial bool    `json:"partial,omitempty"`
+
+	// Offset and Limit select a page of the documents returned by the text, load and notion methods,
+	// Limit is also the number of documents returned by each call of the next method.
+	Offset int `json:"offset,omitempty"`
+	Limit  int `json:"limit,omitempty"`
+}
+
+// parseOptions merges the arguments after the file path into Options and validates them.
+func parseOptions(args []interface{}) (Options, error) {
+	opts := Options{}
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			continue
+		case map[string]interface{}:
+			bytes, err := jsoniter.Marshal(v)
+			if err != nil {
+				return opts, err
+			}
+			if err := strictJSON.Unmarshal(bytes, &opts); err != nil {
+				return opts, invalidArgument("invalid options: %s", err.Error())
+			}
+		case string:
+			if strings.HasPrefix(strings.TrimSpace(v), "{") {
+				if err := strictJSON.UnmarshalFromString(v, &opts); err != nil {
+					return opts, invalidArgument("invalid options: %s", err.Error())
+				}
+				continue
+			}
+			// legacy positional argument: password of the pdf/xlsx file
+			opts.Password = v
+		default:
+			size, ok := toInt(v)
+			if !ok {
+				return opts, invalidArgument("invalid argument %d: %v", i+1, arg)
+			}
+			// legacy positional argument: chunk size
+			opts.ChunkSize = size
+		}
+	}
+	return opts, opts.validate()
+}
+
+// validate checks the options values
+func (opts Options) validate() error {
+	if opts.ChunkSize < 0 {
+		return invalidArgument("chunk_size must not be negative")
+	}
+	if opts.ChunkOverlap != nil {
+		if *opts.ChunkOverlap < 0 {
+			return invalidArgument("chunk_overlap must not be negative")
+		}
+		if opts.ChunkSize > 0 && *opts.ChunkOverlap >= opts.ChunkSize {
+			return invalidArgument("chunk_overlap %d must be less than chunk_size %d", *opts.ChunkOverlap, opts.ChunkSize)
+		}
+	}
+	switch opts.Tables {
+	case "", loaders.PdfTablesInline, loaders.PdfTablesSeparate:
+	default:
+		return invalidArgument("invalid tables: %s, expected %s or %s", opts.Tables, loaders.PdfTablesInline, loaders.PdfTablesSeparate)
+	}
+	switch opts.Annotations {
+	case "", loaders.PdfAnnotationsInline, loaders.PdfAnnotationsSeparate:
+	default:
+		return invalidArgument("invalid annotations: %s, expected %s or %s", opts.Annotations, loaders.PdfAnnotationsInline, loaders.PdfAnnotationsSeparate)
+	}
+	switch opts.TableFormat {
+	case "", loaders.DocxTablesMarkdown, loaders.DocxTablesRows:
+	default:
+		return invalidArgument("invalid table_format: %s, expected %s or %s", opts.TableFormat, loaders.DocxTablesMarkdown, loaders.DocxTablesRows)
+	}
+	switch opts.Notes {
+	case "", loaders.DocxNotesInline, loaders.DocxNotesAppend:
+	default:
+		return invalidArgument("invalid notes: %s, expected %s or %s", opts.Notes, loaders.DocxNotesInline, loaders.DocxNotesAppend)
+	}
+	switch opts.Revisions {
+	case "", loaders.DocxRevisionsAccept, loaders.DocxRevisionsReject, loaders.DocxRevisionsShow:
+	default:
+		return invalidArgument("invalid revisions: %s, expected %s, %s or %s", opts.Revisions,
+			loaders.DocxRevisionsAccept, loaders.DocxRevisionsReject, loaders.DocxRevisionsShow)
+	}
+	if _, err := loaders.ParsePageRanges(opts.Pages); err != nil {
+		return invalidArgument("invalid pages: %s", err.Error())
+	}
+	if opts.MaxDepth < 0 {
+		return invalidArgument("max_depth must not be negative")
+	}
+	if opts.MaxFileSize < 0 {
+		return invalidArgument("max_file_size must not be negative")
+	}
+	if opts.Timeout < 0 {
+		return invalidArgument("timeout must not be negative")
+	}
+	if opts.Offset < 0 {
+		return invalidArgument("offset must not be negative")
+	}
+	if opts.Limit < 0 {
+		return invalidArgument("limit must not be negative")
+	}
+	return nil
+}
+
+// toInt converts the numbers decoded from the grpc payload to int
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float64:
+		if n != float64(int(n)) {
+			return 0, false
+		}
+		return int(n), true
+	case json.Number:
+		i, err := n.Int64()
+		if err != nil {
+			return 0, false
+		}
+		return int(i), true
+	}
+	return 0, false
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("positional", func(t *testing.T) {
+		t.Parallel()
+		opts, err := parseOptions([]interface{}{"password", float64(300)})
+		require.NoError(t, err)
+		assert.Equal(t, "password", opts.Password)
+		assert.Equal(t, 300, opts.ChunkSize)
+	})
+
+	t.Run("map", func(t *testing.T) {
+		t.Parallel()
+		opts, err := parseOptions([]interface{}{map[string]interface{}{
+			"chunk_size":    float64(500),
+			"chunk_overlap": float64(50),
+			"password":      "password",
+			"columns":       []interface{}{"city"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, 500, opts.ChunkSize)
+		require.NotNil(t, opts.ChunkOverlap)
+		assert.Equal(t, 50, *opts.ChunkOverlap)
+		assert.Equal(t, "password", opts.Password)
+		assert.Equal(t, []string{"city"}, opts.Columns)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		opts, err := parseOptions([]interface{}{`{"chunk_size": 200, "encoding": "gbk"}`})
+		require.NoError(t, err)
+		assert.Equal(t, 200, opts.ChunkSize)
+		assert.Equal(t, "gbk", opts.Encoding)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := parseOptions([]interface{}{map[string]interface{}{"chunk_szie": float64(200)}})
+		require.Error(t, err)
+
+		_, err = parseOptions([]interface{}{map[string]interface{}{"chunk_size": float64(100), "chunk_overlap": float64(100)}})
+		require.Error(t, err)
+
+		_, err = parseOptions([]interface{}{true})
+		require.Error(t, err)
+
+		_, err = parseOptions([]interface{}{map[string]interface{}{"timeout": float64(-1)}})
+		require.Error(t, err)
+
+		_, err = parseOptions([]interface{}{map[string]interface{}{"tables": "markdown"}})
+		require.Error(t, err)
+
+		_, err = parseOptions([]interface{}{map[string]interface{}{"pages": "5-1"}})
+		require.Error(t, err)
+
+		_, err = parseOptions([]interface{}{map[string]interface{}{"annotations": "append"}})
+		require.Error(t, err)
+
+		_, err = parseOptions([]interface{}{map[string]interface{}{"table_format": "csv"}})
+		require.Error(t, err)
+
+		_, err = parseOptions([]interface{}{map[string]interface{}{"notes": "footer"}})
+		require.Error(t, err)
+
+		_, err = parseOptions([]interface{}{map[string]interface{}{"revisions": "merge"}})
+		require.Error(t, err)
+	})
+}
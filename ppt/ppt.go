@@ -0,0 +1,230 @@
+// Package ppt reads the text of the PowerPoint 97-2003 binary presentations.
+package ppt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"loader/cfb"
+)
+
+// documentStream is the stream of the records of the presentation
+const documentStream = "PowerPoint Document"
+
+// The types of the records read
+const (
+	recordDocument          = 0x03E8
+	recordSlide             = 0x03EE
+	recordSlidePersist      = 0x03F3
+	recordTextChars         = 0x0FA0
+	recordTextBytes         = 0x0FA8
+	recordSlideListWithText = 0x0FF0
+	recordUserEdit          = 0x0FF5
+	recordPersistDirectory  = 0x1772
+)
+
+// headerSize is the size of the header of the records
+const headerSize = 8
+
+// maxDepth limits the nesting of the containers read
+const maxDepth = 32
+
+// userEditEncryptedSize is the size of the user edit atom with the reference to the encryption session
+const userEditEncryptedSize = 32
+
+// errFormat is returned when a record of the presentation is malformed
+var errFormat = errors.New("malformed powerpoint presentation")
+
+// record is a record of the presentation, the containers have other records as their data
+type record struct {
+	instance  uint16
+	typ       uint16
+	container bool
+	offset    int
+	data      []byte
+}
+
+// readRecords reads the sequence of the records in data, offset is the position of data in the stream
+func readRecords(data []byte, offset int) ([]record, error) {
+	var recs []record
+	for pos := 0; pos+headerSize <= len(data); {
+		verInstance := binary.LittleEndian.Uint16(data[pos:])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		start := pos + headerSize
+		if size < 0 || size > len(data)-start {
+			return recs, fmt.Errorf("%w: record out of its container", errFormat)
+		}
+		recs = append(recs, record{
+			instance:  verInstance >> 4,
+			typ:       binary.LittleEndian.Uint16(data[pos+2:]),
+			container: verInstance&0x0F == 0x0F,
+			offset:    offset + pos,
+			data:      data[start : start+size],
+		})
+		pos = start + size
+	}
+	return recs, nil
+}
+
+// Read returns the texts of each slide in the order of the presentation. The text is read from
+// the slide list of the document and from the shapes of the slides.
+func Read(r io.ReaderAt) ([][]string, error) {
+	streams, err := cfb.ReadStreams(r, documentStream)
+	if err != nil {
+		return nil, err
+	}
+	data := streams[documentStream]
+	if data == nil {
+		return nil, fmt.Errorf("%w: %s", cfb.ErrNotFound, documentStream)
+	}
+
+	top, err := readRecords(data, 0)
+	if err != nil && len(top) == 0 {
+		return nil, err
+	}
+	// the persist directories of the later edits replace the objects of the earlier ones
+	persist := map[uint32]int{}
+	var document *record
+	for i, rec := range top {
+		switch rec.typ {
+		case recordUserEdit:
+			if len(rec.data) >= userEditEncryptedSize {
+				return nil, cfb.ErrEncrypted
+			}
+		case recordPersistDirectory:
+			readPersistDirectory(rec.data, persist)
+		case recordDocument:
+			document = &top[i]
+		}
+	}
+	if document == nil {
+		return [][]string{atomTexts(data, 0)}, nil
+	}
+
+	slides, err := slideList(document.data)
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]string, 0, len(slides))
+	for _, s := range slides {
+		texts := s.texts
+		if offset, ok := persist[s.persistID]; ok {
+			recs, _ := readRecords(data[min(offset, len(data)):], offset)
+			if len(recs) > 0 && recs[0].typ == recordSlide {
+				texts = appendNew(texts, atomTexts(recs[0].data, 0)...)
+			}
+		}
+		result = append(result, texts)
+	}
+	return result, nil
+}
+
+// readPersistDirectory adds the offsets of the persist objects of the directory to persist
+func readPersistDirectory(data []byte, persist map[uint32]int) {
+	for pos := 0; pos+4 <= len(data); {
+		v := binary.LittleEndian.Uint32(data[pos:])
+		id, count := v&0xFFFFF, int(v>>20)
+		pos += 4
+		for i := 0; i < count && pos+4 <= len(data); i++ {
+			persist[id+uint32(i)] = int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+		}
+	}
+}
+
+// slide is a slide of the slide list with the texts stored in the list
+type slide struct {
+	persistID uint32
+	texts     []string
+}
+
+// slideList returns the slides of the slide list of the document, the lists of the
+// master slides and the notes have other instances.
+func slideList(document []byte) ([]slide, error) {
+	recs, err := readRecords(document, 0)
+	if err != nil && len(recs) == 0 {
+		return nil, err
+	}
+	var slides []slide
+	for _, rec := range recs {
+		if rec.typ != recordSlideListWithText || rec.instance != 0 {
+			continue
+		}
+		items, _ := readRecords(rec.data, 0)
+		for _, item := range items {
+			switch item.typ {
+			case recordSlidePersist:
+				if len(item.data) >= 4 {
+					slides = append(slides, slide{persistID: binary.LittleEndian.Uint32(item.data)})
+				}
+			case recordTextChars, recordTextBytes:
+				if len(slides) > 0 {
+					last := &slides[len(slides)-1]
+					last.texts = appendNew(last.texts, atomText(item))
+				}
+			}
+		}
+	}
+	return slides, nil
+}
+
+// atomTexts returns the texts of the text atoms in the records, the containers are read recursively
+func atomTexts(data []byte, depth int) []string {
+	if depth > maxDepth {
+		return nil
+	}
+	recs, _ := readRecords(data, 0)
+	var texts []string
+	for _, rec := range recs {
+		switch {
+		case rec.typ == recordTextChars || rec.typ == recordTextBytes:
+			texts = appendNew(texts, atomText(rec))
+		case rec.container:
+			texts = appendNew(texts, atomTexts(rec.data, depth+1)...)
+		}
+	}
+	return texts
+}
+
+// atomText returns the text of a text atom, the paragraphs and the line breaks become new lines
+func atomText(rec record) string {
+	var chars []uint16
+	if rec.typ == recordTextChars {
+		chars = make([]uint16, len(rec.data)/2)
+		for i := range chars {
+			chars[i] = binary.LittleEndian.Uint16(rec.data[i*2:])
+		}
+	} else {
+		chars = make([]uint16, len(rec.data))
+		for i, b := range rec.data {
+			chars[i] = uint16(b)
+		}
+	}
+	text := string(utf16.Decode(chars))
+	return strings.NewReplacer("\r", "\n", "\v", "\n").Replace(text)
+}
+
+// appendNew appends the trimmed texts not empty and not yet in the list
+func appendNew(list []string, texts ...string) []string {
+	for _, text := range texts {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		found := false
+		for _, s := range list {
+			if s == text {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, text)
+		}
+	}
+	return list
+}
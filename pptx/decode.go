@@ -4,71 +4,381 @@ import (
 	"archive/zip"
 	"encoding/xml"
 	"io"
+	"path"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// slidePattern matches the names of the slide files
+var slidePattern = regexp.MustCompile(`slides/slide(\d+)\.xml$`)
+
 func isSlide(file *zip.File) bool {
-	matched, err := regexp.MatchString("slides/slide(\\d+).xml", file.Name)
+	return slidePattern.MatchString(file.Name)
+}
+
+// Read returns the texts of each slide in the order of the presentation
+func Read(r io.ReaderAt, size int64) ([][]string, error) {
+	slides, err := ReadSlides(r, size, false)
 	if err != nil {
-		return false
+		return nil, err
+	}
+	texts := make([][]string, 0, len(slides))
+	for _, slide := range slides {
+		texts = append(texts, slide.Texts)
 	}
-	return matched
+	return texts, nil
 }
-func Read(r io.ReaderAt, size int64) ([][]string, error) {
+
+// Slide is a slide of the presentation
+type Slide struct {
+	// Name is the name of the slide file
+	Name string
+	// Number is the 1-based position of the slide in the presentation
+	Number int
+	// Texts are the texts of the slide, "\n" starts each paragraph
+	Texts []string
+	// Title is the text of the title placeholder
+	Title string
+	// Hidden is true for the slides hidden in the slide show
+	Hidden bool
+	// Notes are the speaker notes, only read when asked
+	Notes string
+}
+
+// ReadSlides returns the slides in the order of the presentation, the speaker notes are read when notes is true
+func ReadSlides(r io.ReaderAt, size int64, notes bool) ([]Slide, error) {
 	zipReader, err := zip.NewReader(r, size)
 	if err != nil {
 		return nil, err
 	}
-
-	texts := make([][]string, 0)
+	files := map[string]*zip.File{}
 	for _, file := range zipReader.File {
-		if isSlide(file) {
-			slideTexts, err := getSingleSlide(file)
-			if err != nil {
+		files[file.Name] = file
+	}
+
+	names := slideNames(zipReader, files)
+	slides := make([]Slide, 0, len(names))
+	for i, name := range names {
+		slide, err := getSingleSlide(files[name])
+		if err != nil {
+			return nil, err
+		}
+		slide.Name = name
+		slide.Number = i + 1
+		if notes {
+			if slide.Notes, err = getSlideNotes(files, name); err != nil {
 				return nil, err
 			}
-			texts = append(texts, slideTexts)
 		}
+		slides = append(slides, slide)
 	}
-	return texts, nil
+	return slides, nil
+}
 
+// presentation is the list of the slides in ppt/presentation.xml
+type presentation struct {
+	Slides []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sldIdLst>sldId"`
 }
 
-func getSingleSlide(s *zip.File) ([]string, error) {
-	var slideTexts []string
-	r, err := s.Open()
+// slideNames returns the names of the slide files in the order of the slide list of the presentation,
+// the slides are sorted by the numbers of their names when the list can not be read
+func slideNames(zipReader *zip.Reader, files map[string]*zip.File) []string {
+	if names := listedSlides(files); len(names) > 0 {
+		return names
+	}
+	names := []string{}
+	for _, file := range zipReader.File {
+		if isSlide(file) {
+			names = append(names, file.Name)
+		}
+	}
+	number := func(name string) int {
+		n, _ := strconv.Atoi(slidePattern.FindStringSubmatch(name)[1])
+		return n
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return number(names[i]) < number(names[j])
+	})
+	return names
+}
+
+// listedSlides returns the slide files of the slide list of ppt/presentation.xml
+func listedSlides(files map[string]*zip.File) []string {
+	const part = "ppt/presentation.xml"
+	file, ok := files[part]
+	if !ok {
+		return nil
+	}
+	data, err := readFile(file)
+	if err != nil {
+		return nil
+	}
+	var doc presentation
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	rels, err := readRelationships(files, part)
+	if err != nil {
+		return nil
+	}
+	targets := map[string]string{}
+	for _, rel := range rels.Relationships {
+		if strings.HasSuffix(rel.Type, "/slide") && rel.TargetMode != "External" {
+			targets[rel.ID] = rel.target(part)
+		}
+	}
+
+	names := []string{}
+	for _, s := range doc.Slides {
+		if name, ok := targets[s.RelationshipID]; ok && files[name] != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Media is an image file of the presentation
+type Media struct {
+	Name string
+	Data []byte
+}
+
+type relationships struct {
+	Relationships []relationship `xml:"Relationship"`
+}
+
+type relationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
+}
+
+// target returns the name of the file of the relationship of the part
+func (rel relationship) target(part string) string {
+	if strings.HasPrefix(rel.Target, "/") {
+		return strings.TrimPrefix(rel.Target, "/")
+	}
+	return path.Join(path.Dir(part), rel.Target)
+}
+
+// readRelationships reads the relationships of the part, there are none when the part has no relationships file
+func readRelationships(files map[string]*zip.File, part string) (relationships, error) {
+	dir, name := path.Split(part)
+	rels, ok := files[dir+"_rels/"+name+".rels"]
+	if !ok {
+		return relationships{}, nil
+	}
+	data, err := readFile(rels)
+	if err != nil {
+		return relationships{}, err
+	}
+	var doc relationships
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return relationships{}, err
+	}
+	return doc, nil
+}
+
+// ReadMedia returns the image files of each slide, the slides are in the order of Read
+func ReadMedia(r io.ReaderAt, size int64) ([][]Media, error) {
+	zipReader, err := zip.NewReader(r, size)
 	if err != nil {
 		return nil, err
 	}
+	files := map[string]*zip.File{}
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+
+	media := make([][]Media, 0)
+	for _, name := range slideNames(zipReader, files) {
+		slideMedia, err := getSlideMedia(files, name)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, slideMedia)
+	}
+	return media, nil
+}
+
+// getSlideMedia reads the images of the relationships of the slide
+func getSlideMedia(files map[string]*zip.File, slide string) ([]Media, error) {
+	doc, err := readRelationships(files, slide)
+	if err != nil {
+		return nil, err
+	}
+
+	media := []Media{}
+	seen := map[string]bool{}
+	for _, rel := range doc.Relationships {
+		if !strings.HasSuffix(rel.Type, "/image") || rel.TargetMode == "External" {
+			continue
+		}
+		target := rel.target(slide)
+		file, ok := files[target]
+		if !ok || seen[target] {
+			continue
+		}
+		seen[target] = true
+		data, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, Media{Name: target, Data: data})
+	}
+	return media, nil
+}
+
+// readFile returns the content of the file of the zip archive
+func readFile(file *zip.File) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// getSingleSlide reads the texts of the slide, the title is the text of the title placeholder
+func getSingleSlide(s *zip.File) (Slide, error) {
+	var slide Slide
+	r, err := s.Open()
+	if err != nil {
+		return Slide{}, err
+	}
 	defer r.Close()
 	d := xml.NewDecoder(r)
+	// shape is the text of the shape being read, its placeholder tells if it is the title
+	var shape []string
+	depth, shapeDepth, title := 0, 0, false
 	for {
 		tok, err := d.Token()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return Slide{}, err
+		}
+		if end, ok := tok.(xml.EndElement); ok {
+			if depth == shapeDepth && end.Name.Local == "sp" {
+				if title && slide.Title == "" {
+					slide.Title = strings.Join(strings.Fields(strings.Join(shape, " ")), " ")
+				}
+				shapeDepth, shape, title = 0, nil, false
+			}
+			depth--
+			continue
 		}
 		t, ok := tok.(xml.StartElement)
 		if !ok {
 			continue
 		}
+		depth++
+
+		switch t.Name.Local {
+		case "sld":
+			slide.Hidden = depth == 1 && attr(t, "show") == "0"
+		case "sp":
+			if shapeDepth == 0 {
+				shapeDepth = depth
+			}
+		case "ph":
+			if placeholder := attr(t, "type"); shapeDepth > 0 && (placeholder == "title" || placeholder == "ctrTitle") {
+				title = true
+			}
+		}
 
 		if t.Name.Local == "t" {
 			charDataTok, err := d.Token()
 			if err != nil {
-				return nil, err
+				return Slide{}, err
+			}
+			if _, ok := charDataTok.(xml.EndElement); ok {
+				depth--
 			}
 			charData, ok := charDataTok.(xml.CharData)
 			if !ok {
 				continue
 			}
-			slideTexts = append(slideTexts, string(charData))
+			slide.Texts = append(slide.Texts, string(charData))
+			if shapeDepth > 0 {
+				shape = append(shape, string(charData))
+			}
 		}
 		if t.Name.Local == "p" {
-			slideTexts = append(slideTexts, "\n")
+			slide.Texts = append(slide.Texts, "\n")
+		}
+	}
+	return slide, nil
+}
+
+// getSlideNotes returns the text of the body placeholder of the notes of the slide,
+// the paragraphs are on separate lines
+func getSlideNotes(files map[string]*zip.File, slide string) (string, error) {
+	rels, err := readRelationships(files, slide)
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if !strings.HasSuffix(rel.Type, "/notesSlide") || rel.TargetMode == "External" {
+			continue
+		}
+		file, ok := files[rel.target(slide)]
+		if !ok {
+			continue
+		}
+		data, err := readFile(file)
+		if err != nil {
+			return "", err
+		}
+		var notes notesSlide
+		if err := xml.Unmarshal(data, &notes); err != nil {
+			return "", err
+		}
+		return notes.text(), nil
+	}
+	return "", nil
+}
+
+// notesSlide is the notes page of a slide, the notes are in the body placeholder
+type notesSlide struct {
+	Shapes []struct {
+		Placeholder struct {
+			Type string `xml:"type,attr"`
+		} `xml:"nvSpPr>nvPr>ph"`
+		Paragraphs []struct {
+			Runs []string `xml:"r>t"`
+		} `xml:"txBody>p"`
+	} `xml:"cSld>spTree>sp"`
+}
+
+// text returns the paragraphs of the body placeholders
+func (n notesSlide) text() string {
+	lines := []string{}
+	for _, shape := range n.Shapes {
+		if shape.Placeholder.Type != "body" {
+			continue
+		}
+		for _, p := range shape.Paragraphs {
+			if line := strings.TrimSpace(strings.Join(p.Runs, "")); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// attr returns the value of the attribute of the element
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value
 		}
 	}
-	return slideTexts, nil
+	return ""
 }
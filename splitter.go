@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+
+	"loader/filetype"
+	"loader/schema"
+	"loader/textsplitter"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// The names of the text splitters accepted by the splitter option
+const (
+	splitterRecursive = "recursive"
+	splitterToken     = "token"
+	splitterMarkdown  = "markdown"
+	splitterNone      = "none"
+)
+
+// splitterName returns the splitter used for the file type when the option is not set
+func splitterName(ftype string, opts Options) string {
+	name := strings.ToLower(strings.TrimSpace(opts.Splitter))
+	if name != "" {
+		return name
+	}
+	if ftype == filetype.MD || (ftype == filetype.PDF && opts.Tables != "") || ((ftype == filetype.DOCX || ftype == filetype.ODT) && opts.Markdown) {
+		// the markdown splitter keeps the rows of the tables together and the headings in the chunks
+		return splitterMarkdown
+	}
+	return splitterRecursive
+}
+
+// newSplitter creates the text splitter of the file type with the options,
+// a nil splitter is returned for the "none" splitter so the documents are not split.
+func newSplitter(ftype string, opts Options) (textsplitter.TextSplitter, error) {
+	options := []textsplitter.Option{textsplitter.WithChunkSize(opts.ChunkSize)}
+	if opts.ChunkOverlap != nil {
+		options = append(options, textsplitter.WithChunkOverlap(*opts.ChunkOverlap))
+	} else if opts.ChunkSize > 0 {
+		// the default overlap must stay below the chunk size, the token splitter would not advance otherwise
+		overlap := min(textsplitter.DefaultOptions().ChunkOverlap, opts.ChunkSize-1)
+		options = append(options, textsplitter.WithChunkOverlap(overlap))
+	}
+
+	switch splitterName(ftype, opts) {
+	case splitterNone:
+		return nil, nil
+
+	case splitterRecursive:
+		if len(opts.Separators) > 0 {
+			options = append(options, textsplitter.WithSeparators(opts.Separators))
+		}
+		if opts.KeepSeparator != nil {
+			options = append(options, textsplitter.WithKeepSeparator(*opts.KeepSeparator))
+		}
+		return textsplitter.NewRecursiveCharacter(options...), nil
+
+	case splitterToken:
+		if opts.ModelName != "" {
+			options = append(options, textsplitter.WithModelName(opts.ModelName))
+			// the encoding takes precedence over the model in the token splitter
+			options = append(options, textsplitter.WithEncodingName(""))
+		}
+		if opts.EncodingName != "" {
+			options = append(options, textsplitter.WithEncodingName(opts.EncodingName))
+		}
+		return textsplitter.NewTokenSplitter(options...), nil
+
+	case splitterMarkdown:
+		codeBlocks := true
+		if opts.CodeBlocks != nil {
+			codeBlocks = *opts.CodeBlocks
+		}
+		if len(opts.Separators) > 0 {
+			// separators of the recursive splitter used for the long paragraphs
+			second := textsplitter.NewRecursiveCharacter(append(options, textsplitter.WithSeparators(opts.Separators))...)
+			options = append(options, textsplitter.WithSecondSplitter(second))
+		}
+		options = append(options, textsplitter.WithCodeBlocks(codeBlocks), textsplitter.WithJoinTableRows(opts.JoinTableRows))
+		if opts.HeadingHierarchy != nil {
+			options = append(options, textsplitter.WithHeadingHierarchy(*opts.HeadingHierarchy))
+		}
+		return textsplitter.NewMarkdownTextSplitter(options...), nil
+	}
+	return nil, invalidArgument("unknown splitter: %s", opts.Splitter)
+}
+
+// splitDocuments splits a text or a list of documents without reading any file.
+// The documents are the ones returned by the load method, strings in the list are used as page contents.
+func splitDocuments(input interface{}, args []interface{}) ([]schema.Document, error) {
+	opts, err := parseOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []schema.Document
+	switch v := input.(type) {
+	case string:
+		docs = []schema.Document{{PageContent: v, Metadata: map[string]any{}}}
+	case []interface{}:
+		for i, item := range v {
+			if text, ok := item.(string); ok {
+				docs = append(docs, schema.Document{PageContent: text, Metadata: map[string]any{}})
+				continue
+			}
+			bytes, err := jsoniter.Marshal(item)
+			if err != nil {
+				return nil, err
+			}
+			var doc schema.Document
+			if err := jsoniter.Unmarshal(bytes, &doc); err != nil {
+				return nil, invalidArgument("invalid document %d: %s", i, err.Error())
+			}
+			if doc.Metadata == nil {
+				doc.Metadata = map[string]any{}
+			}
+			docs = append(docs, doc)
+		}
+	default:
+		return nil, invalidArgument("invalid text, a string or a list of documents is required")
+	}
+
+	splitter, err := newSplitter("", opts)
+	if err != nil {
+		return nil, err
+	}
+	if splitter == nil {
+		return docs, nil
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"loader/textsplitter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSplitter(t *testing.T) {
+	t.Parallel()
+
+	splitter, err := newSplitter("PDF", Options{ChunkSize: 300})
+	require.NoError(t, err)
+	assert.IsType(t, textsplitter.RecursiveCharacter{}, splitter)
+	assert.Equal(t, 300, splitter.(textsplitter.RecursiveCharacter).ChunkSize)
+
+	splitter, err = newSplitter("MD", Options{})
+	require.NoError(t, err)
+	assert.IsType(t, &textsplitter.MarkdownTextSplitter{}, splitter)
+
+	splitter, err = newSplitter("PDF", Options{Tables: "inline"})
+	require.NoError(t, err)
+	assert.IsType(t, &textsplitter.MarkdownTextSplitter{}, splitter)
+
+	splitter, err = newSplitter("DOCX", Options{Markdown: true})
+	require.NoError(t, err)
+	assert.IsType(t, &textsplitter.MarkdownTextSplitter{}, splitter)
+
+	splitter, err = newSplitter("ODT", Options{Markdown: true})
+	require.NoError(t, err)
+	assert.IsType(t, &textsplitter.MarkdownTextSplitter{}, splitter)
+
+	splitter, err = newSplitter("TEXT", Options{Splitter: "token", ModelName: "gpt-4"})
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4", splitter.(textsplitter.TokenSplitter).ModelName)
+	assert.Empty(t, splitter.(textsplitter.TokenSplitter).EncodingName)
+
+	opts, err := parseOptions([]interface{}{map[string]interface{}{"splitter": "token", "chunk_size": float64(50)}})
+	require.NoError(t, err)
+	splitter, err = newSplitter("TEXT", opts)
+	require.NoError(t, err)
+	assert.Equal(t, 50, splitter.(textsplitter.TokenSplitter).ChunkSize)
+	assert.Equal(t, 49, splitter.(textsplitter.TokenSplitter).ChunkOverlap)
+
+	splitter, err = newSplitter("TEXT", Options{Splitter: "none"})
+	require.NoError(t, err)
+	assert.Nil(t, splitter)
+
+	_, err = newSplitter("TEXT", Options{Splitter: "sentence"})
+	require.Error(t, err)
+}
+
+func TestSplitDocuments(t *testing.T) {
+	t.Parallel()
+
+	text := "The first paragraph.\n\nThe second paragraph."
+	docs, err := splitDocuments(text, []interface{}{map[string]interface{}{"chunk_size": float64(25), "chunk_overlap": float64(0)}})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "The first paragraph.", docs[0].PageContent)
+	assert.Equal(t, "The second paragraph.", docs[1].PageContent)
+
+	input := []interface{}{
+		map[string]interface{}{"PageContent": text, "Metadata": map[string]interface{}{"page": float64(1)}},
+		"Foo Bar Baz",
+	}
+	docs, err = splitDocuments(input, []interface{}{map[string]interface{}{"chunk_size": float64(25), "chunk_overlap": float64(0)}})
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.Equal(t, map[string]any{"page": float64(1)}, docs[1].Metadata)
+	assert.Equal(t, "Foo Bar Baz", docs[2].PageContent)
+
+	_, err = splitDocuments(float64(1), nil)
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package textsplitter
 
 import (
 	"errors"
+	"iter"
 	"log"
 	"strings"
 
@@ -40,25 +41,60 @@ func CreateDocuments(textSplitter TextSplitter, texts []string, metadatas []map[
 	documents := make([]schema.Document, 0)
 
 	for i := 0; i < len(texts); i++ {
-		chunks, err := textSplitter.SplitText(texts[i])
+		chunks, err := splitDocument(textSplitter, texts[i], metadatas[i])
 		if err != nil {
 			return nil, err
 		}
+		documents = append(documents, chunks...)
+	}
+
+	return documents, nil
+}
 
-		for _, chunk := range chunks {
-			// Copy the document metadata
-			curMetadata := make(map[string]any, len(metadatas[i]))
-			for key, value := range metadatas[i] {
-				curMetadata[key] = value
+// SplitDocumentsIter splits the documents of the iterator one by one and yields the chunks,
+// so the documents don't need to be loaded all at once. The iteration ends after the first error.
+func SplitDocumentsIter(textSplitter TextSplitter, documents iter.Seq2[schema.Document, error]) iter.Seq2[schema.Document, error] {
+	return func(yield func(schema.Document, error) bool) {
+		for document, err := range documents {
+			if err != nil {
+				yield(schema.Document{}, err)
+				return
 			}
 
-			documents = append(documents, schema.Document{
-				PageContent: chunk,
-				Metadata:    curMetadata,
-			})
+			chunks, err := splitDocument(textSplitter, document.PageContent, document.Metadata)
+			if err != nil {
+				yield(schema.Document{}, err)
+				return
+			}
+			for _, chunk := range chunks {
+				if !yield(chunk, nil) {
+					return
+				}
+			}
 		}
 	}
+}
+
+// splitDocument splits the text into documents, each with a copy of the metadata.
+func splitDocument(textSplitter TextSplitter, text string, metadata map[string]any) ([]schema.Document, error) {
+	chunks, err := textSplitter.SplitText(text)
+	if err != nil {
+		return nil, err
+	}
 
+	documents := make([]schema.Document, 0, len(chunks))
+	for _, chunk := range chunks {
+		// Copy the document metadata
+		curMetadata := make(map[string]any, len(metadata))
+		for key, value := range metadata {
+			curMetadata[key] = value
+		}
+
+		documents = append(documents, schema.Document{
+			PageContent: chunk,
+			Metadata:    curMetadata,
+		})
+	}
 	return documents, nil
 }
 
@@ -130,4 +166,4 @@ func shouldPop(chunkOverlap, chunkSize, total, splitLen, separatorLen, currentDo
 	}
 
 	return currentDocLen > 0 && (total > chunkOverlap || (total+splitLen+separatorLen > chunkSize && total > 0))
-}
\ No newline at end of file
+}
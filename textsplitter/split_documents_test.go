@@ -0,0 +1,54 @@
+package textsplitter
+
+import (
+	"errors"
+	"testing"
+
+	"loader/schema"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitDocumentsIter(t *testing.T) {
+	t.Parallel()
+
+	splitter := NewRecursiveCharacter(WithChunkSize(10), WithChunkOverlap(0), WithSeparators([]string{" "}))
+	docs := []schema.Document{
+		{PageContent: "first page of the document", Metadata: map[string]any{"page": 1}},
+		{PageContent: "second page", Metadata: map[string]any{"page": 2}},
+	}
+	expected, err := SplitDocuments(splitter, docs)
+	require.NoError(t, err)
+
+	seq := func(yield func(schema.Document, error) bool) {
+		for _, doc := range docs {
+			if !yield(doc, nil) {
+				return
+			}
+		}
+	}
+	chunks := []schema.Document{}
+	for chunk, err := range SplitDocumentsIter(splitter, seq) {
+		require.NoError(t, err)
+		chunks = append(chunks, chunk)
+	}
+	assert.Equal(t, expected, chunks)
+
+	// the iteration stops at the first error
+	errLoad := errors.New("load failed")
+	failing := func(yield func(schema.Document, error) bool) {
+		if yield(docs[1], nil) {
+			yield(schema.Document{}, errLoad)
+		}
+	}
+	chunks = []schema.Document{}
+	for chunk, err := range SplitDocumentsIter(splitter, failing) {
+		if err != nil {
+			require.ErrorIs(t, err, errLoad)
+			break
+		}
+		chunks = append(chunks, chunk)
+	}
+	assert.Len(t, chunks, 2)
+}
@@ -308,3 +308,22 @@ func encodeRune(r uint16) []byte {
 	n := utf8.EncodeRune(buf, runeValue[0])
 	return buf[:n]
 }
+
+// NewEncodingReader converts the content of reader from the given encoding to UTF-8,
+// the byte order mark at the beginning of the content is removed.
+func NewEncodingReader(reader io.Reader, encoding string) (io.Reader, error) {
+	label := strings.ToLower(strings.TrimSpace(encoding))
+	if label != "" && label != "utf-8" && label != "utf8" {
+		var err error
+		reader, err = charset.NewReaderLabel(label, reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+	br := bufio.NewReader(reader)
+	bom, err := br.Peek(3)
+	if err == nil && bytes.Equal(bom, []byte("\ufeff")) {
+		br.Discard(len(bom))
+	}
+	return br, nil
+}
@@ -0,0 +1,555 @@
+// Package xls reads the cell values of the Excel 97-2003 binary workbooks.
+package xls
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"loader/cfb"
+)
+
+// The streams of the workbook, Book is the stream of the workbooks before Excel 97
+const (
+	workbookStream = "Workbook"
+	bookStream     = "Book"
+)
+
+// The types of the records read
+const (
+	recordFormula    = 0x0006
+	recordEOF        = 0x000A
+	recordDateMode   = 0x0022
+	recordFilePass   = 0x002F
+	recordContinue   = 0x003C
+	recordBoundSheet = 0x0085
+	recordMulRK      = 0x00BD
+	recordSST        = 0x00FC
+	recordLabelSST   = 0x00FD
+	recordNumber     = 0x0203
+	recordLabel      = 0x0204
+	recordBoolErr    = 0x0205
+	recordString     = 0x0207
+	recordArray      = 0x0221
+	recordTable      = 0x0236
+	recordRK         = 0x027E
+	recordShrFmla    = 0x04BC
+	recordFormat     = 0x041E
+	recordXF         = 0x00E0
+	recordBOF        = 0x0809
+)
+
+// biff8 is the version in the BOF record of the Excel 97 workbooks
+const biff8 = 0x0600
+
+// maxRows and maxColumns are the limits of the cells of a sheet in Excel 97, maxCells bounds
+// the cells of the rows of a sheet, the cells after are dropped
+const (
+	maxRows    = 1 << 16
+	maxColumns = 1 << 8
+	maxCells   = 1 << 20
+)
+
+// errFormat is returned when a record of the workbook is malformed
+var errFormat = errors.New("malformed excel workbook")
+
+// ErrVersion is returned for the workbooks saved before Excel 97
+var ErrVersion = errors.New("unsupported excel version")
+
+// Sheet is a worksheet of the workbook
+type Sheet struct {
+	Name string
+	// Rows are the values of the cells, the trailing empty cells of the rows are dropped
+	Rows [][]string
+}
+
+// Read returns the worksheets of the workbook in their order, the numbers formatted as dates
+// are written in the ISO format.
+func Read(r io.ReaderAt) ([]Sheet, error) {
+	streams, err := cfb.ReadStreams(r, workbookStream, bookStream)
+	if err != nil {
+		return nil, err
+	}
+	data := streams[workbookStream]
+	if data == nil {
+		if streams[bookStream] != nil {
+			return nil, ErrVersion
+		}
+		return nil, fmt.Errorf("%w: %s", cfb.ErrNotFound, workbookStream)
+	}
+
+	w, err := readGlobals(data)
+	if err != nil {
+		return nil, err
+	}
+	sheets := make([]Sheet, 0, len(w.sheets))
+	for _, s := range w.sheets {
+		rows, err := w.readSheet(data, s.offset)
+		if err != nil {
+			return nil, fmt.Errorf("sheet %s: %w", s.name, err)
+		}
+		sheets = append(sheets, Sheet{Name: s.name, Rows: rows})
+	}
+	return sheets, nil
+}
+
+// record is a record of the workbook stream
+type record struct {
+	typ  uint16
+	data []byte
+}
+
+// records reads the records of the stream from the offset until the end of the stream
+type records struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next record, false at the end of the stream
+func (r *records) next() (record, bool, error) {
+	if r.pos+4 > len(r.data) {
+		return record{}, false, nil
+	}
+	typ := binary.LittleEndian.Uint16(r.data[r.pos:])
+	size := int(binary.LittleEndian.Uint16(r.data[r.pos+2:]))
+	start := r.pos + 4
+	if start+size > len(r.data) {
+		return record{}, false, fmt.Errorf("%w: record 0x%04X out of the stream", errFormat, typ)
+	}
+	r.pos = start + size
+	return record{typ: typ, data: r.data[start : start+size]}, true, nil
+}
+
+// continues returns the data of the CONTINUE records following the current record
+func (r *records) continues() ([][]byte, error) {
+	var segments [][]byte
+	for r.pos+4 <= len(r.data) && binary.LittleEndian.Uint16(r.data[r.pos:]) == recordContinue {
+		rec, _, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, rec.data)
+	}
+	return segments, nil
+}
+
+// boundSheet is a sheet in the workbook globals
+type boundSheet struct {
+	name   string
+	offset uint32
+}
+
+// workbook is the content of the workbook globals needed to read the cells
+type workbook struct {
+	sheets  []boundSheet
+	strings []string
+	// dateFormats are the indexes of the cell formats formatting the numbers as dates
+	dateFormats map[int]bool
+	date1904    bool
+}
+
+// readGlobals reads the sheets, the shared strings and the formats from the workbook globals
+func readGlobals(data []byte) (*workbook, error) {
+	recs := &records{data: data}
+	bof, ok, err := recs.next()
+	if err != nil {
+		return nil, err
+	}
+	if !ok || bof.typ != recordBOF || len(bof.data) < 4 {
+		return nil, fmt.Errorf("%w: no BOF record", errFormat)
+	}
+	if binary.LittleEndian.Uint16(bof.data) != biff8 {
+		return nil, ErrVersion
+	}
+
+	w := &workbook{dateFormats: map[int]bool{}}
+	formats := map[uint16]string{}
+	var xfs []uint16
+	for {
+		rec, ok, err := recs.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || rec.typ == recordEOF {
+			break
+		}
+		switch rec.typ {
+		case recordFilePass:
+			return nil, cfb.ErrEncrypted
+		case recordDateMode:
+			w.date1904 = len(rec.data) >= 2 && binary.LittleEndian.Uint16(rec.data) == 1
+		case recordBoundSheet:
+			if len(rec.data) < 8 {
+				return nil, fmt.Errorf("%w: bad BOUNDSHEET record", errFormat)
+			}
+			// only the worksheets, the charts and the macro sheets have no cells to read
+			if rec.data[5] != 0 {
+				continue
+			}
+			name, _ := readString(&segments{data: [][]byte{rec.data[6:]}}, int(rec.data[6]), 1)
+			w.sheets = append(w.sheets, boundSheet{name: name, offset: binary.LittleEndian.Uint32(rec.data)})
+		case recordFormat:
+			if len(rec.data) < 5 {
+				continue
+			}
+			code, _ := readString(&segments{data: [][]byte{rec.data[2:]}}, int(binary.LittleEndian.Uint16(rec.data[2:])), 2)
+			formats[binary.LittleEndian.Uint16(rec.data)] = code
+		case recordXF:
+			if len(rec.data) >= 4 {
+				xfs = append(xfs, binary.LittleEndian.Uint16(rec.data[2:]))
+			}
+		case recordSST:
+			more, err := recs.continues()
+			if err != nil {
+				return nil, err
+			}
+			if w.strings, err = readSST(append([][]byte{rec.data}, more...)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i, ifmt := range xfs {
+		if isDateFormat(ifmt, formats[ifmt]) {
+			w.dateFormats[i] = true
+		}
+	}
+	return w, nil
+}
+
+// readSheet reads the values of the cells of the sheet at the offset of the stream
+func (w *workbook) readSheet(data []byte, offset uint32) ([][]string, error) {
+	if uint64(offset) >= uint64(len(data)) {
+		return nil, fmt.Errorf("%w: sheet out of the stream", errFormat)
+	}
+	recs := &records{data: data, pos: int(offset)}
+	bof, ok, err := recs.next()
+	if err != nil {
+		return nil, err
+	}
+	if !ok || bof.typ != recordBOF {
+		return nil, fmt.Errorf("%w: no BOF record", errFormat)
+	}
+
+	var rows [][]string
+	cells := 0
+	set := func(row, col uint16, value string) {
+		if value == "" || int(row) >= maxRows || int(col) >= maxColumns {
+			return
+		}
+		// the rows are grown to the column of the cell, the empty rows are nil
+		grow := int(col) + 1
+		if int(row) < len(rows) {
+			grow -= len(rows[row])
+		}
+		if grow > 0 && cells+grow > maxCells {
+			return
+		}
+		cells += max(grow, 0)
+		for len(rows) <= int(row) {
+			rows = append(rows, nil)
+		}
+		for len(rows[row]) <= int(col) {
+			rows[row] = append(rows[row], "")
+		}
+		rows[row][col] = value
+	}
+
+	for {
+		rec, ok, err := recs.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || rec.typ == recordEOF {
+			break
+		}
+		if len(rec.data) < 6 {
+			continue
+		}
+		row := binary.LittleEndian.Uint16(rec.data)
+		col := binary.LittleEndian.Uint16(rec.data[2:])
+		xf := int(binary.LittleEndian.Uint16(rec.data[4:]))
+		switch rec.typ {
+		case recordLabelSST:
+			if len(rec.data) >= 10 {
+				if i := binary.LittleEndian.Uint32(rec.data[6:]); uint64(i) < uint64(len(w.strings)) {
+					set(row, col, w.strings[i])
+				}
+			}
+		case recordLabel:
+			if len(rec.data) >= 8 {
+				s, _ := readString(&segments{data: [][]byte{rec.data[8:]}}, int(binary.LittleEndian.Uint16(rec.data[6:])), 0)
+				set(row, col, s)
+			}
+		case recordNumber:
+			if len(rec.data) >= 14 {
+				set(row, col, w.number(math.Float64frombits(binary.LittleEndian.Uint64(rec.data[6:])), xf))
+			}
+		case recordRK:
+			if len(rec.data) >= 10 {
+				set(row, col, w.number(rk(binary.LittleEndian.Uint32(rec.data[6:])), xf))
+			}
+		case recordMulRK:
+			// the records of the cells are followed by the last column
+			for i, c := 0, col; 4+i*6+6 <= len(rec.data)-2; i, c = i+1, c+1 {
+				cell := rec.data[4+i*6:]
+				set(row, c, w.number(rk(binary.LittleEndian.Uint32(cell[2:])), int(binary.LittleEndian.Uint16(cell))))
+			}
+		case recordBoolErr:
+			if len(rec.data) >= 8 {
+				set(row, col, boolErr(rec.data[6], rec.data[7] != 0))
+			}
+		case recordFormula:
+			if len(rec.data) < 14 {
+				continue
+			}
+			value := rec.data[6:14]
+			if binary.LittleEndian.Uint16(value[6:]) != 0xFFFF {
+				set(row, col, w.number(math.Float64frombits(binary.LittleEndian.Uint64(value)), xf))
+				continue
+			}
+			switch value[0] {
+			case 0:
+				// the string result is in the STRING record following the formula,
+				// after the records of the shared and array formulas
+				pos := recs.pos
+				next, ok, err := recs.next()
+				for err == nil && ok && (next.typ == recordShrFmla || next.typ == recordArray || next.typ == recordTable) {
+					next, ok, err = recs.next()
+				}
+				if err != nil {
+					return nil, err
+				}
+				if !ok || next.typ != recordString {
+					// the record is read with the cells
+					recs.pos = pos
+					continue
+				}
+				if len(next.data) >= 3 {
+					more, err := recs.continues()
+					if err != nil {
+						return nil, err
+					}
+					s, _ := readString(&segments{data: append([][]byte{next.data[2:]}, more...)}, int(binary.LittleEndian.Uint16(next.data)), 0)
+					set(row, col, s)
+				}
+			case 1:
+				set(row, col, boolErr(value[2], false))
+			case 2:
+				set(row, col, boolErr(value[2], true))
+			}
+		}
+	}
+	return rows, nil
+}
+
+// number formats the value of the cell, as a date when the format of the cell is a date format
+func (w *workbook) number(v float64, xf int) string {
+	if w.dateFormats[xf] && v >= 0 && v < 2958466 {
+		return formatDate(v, w.date1904)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// rk returns the value of the number stored in the rk format
+func rk(v uint32) float64 {
+	var f float64
+	if v&0x02 != 0 {
+		f = float64(int32(v) >> 2)
+	} else {
+		f = math.Float64frombits(uint64(v&0xFFFFFFFC) << 32)
+	}
+	if v&0x01 != 0 {
+		f /= 100
+	}
+	return f
+}
+
+// errorValues are the texts of the error codes of the cells
+var errorValues = map[byte]string{
+	0x00: "#NULL!",
+	0x07: "#DIV/0!",
+	0x0F: "#VALUE!",
+	0x17: "#REF!",
+	0x1D: "#NAME?",
+	0x24: "#NUM!",
+	0x2A: "#N/A",
+}
+
+// boolErr returns the text of the boolean or the error value of a cell
+func boolErr(v byte, isErr bool) string {
+	if isErr {
+		return errorValues[v]
+	}
+	if v != 0 {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// formatDate formats the serial number of the date, the time is added when it is not midnight
+func formatDate(v float64, date1904 bool) string {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days := math.Floor(v)
+	seconds := math.Round((v - days) * 86400)
+	t := base.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	switch {
+	case seconds == 0:
+		return t.Format(time.DateOnly)
+	case days == 0:
+		return t.Format(time.TimeOnly)
+	}
+	return t.Format(time.DateTime)
+}
+
+// isDateFormat checks the built-in date formats and the date codes of the custom formats
+func isDateFormat(ifmt uint16, code string) bool {
+	if (ifmt >= 14 && ifmt <= 22) || (ifmt >= 45 && ifmt <= 47) {
+		return true
+	}
+	if code == "" {
+		return false
+	}
+	// the quoted texts, the escaped characters and the colors are not date codes
+	quoted, bracket := false, false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '\\' || c == '_' || c == '*':
+			i++
+		case c == '[':
+			bracket = true
+		case c == ']':
+			bracket = false
+		case bracket:
+		case strings.IndexByte("dDmMyYhHsS", c) >= 0:
+			return true
+		}
+	}
+	return false
+}
+
+// segments reads the data of a record and its CONTINUE records
+type segments struct {
+	data [][]byte
+	seg  int
+	pos  int
+}
+
+// read returns the next n bytes, crossing the boundaries of the segments
+func (s *segments) read(n int) ([]byte, error) {
+	if s.seg < len(s.data) && s.pos+n <= len(s.data[s.seg]) {
+		b := s.data[s.seg][s.pos : s.pos+n]
+		s.pos += n
+		return b, nil
+	}
+	b := make([]byte, 0, n)
+	for len(b) < n {
+		if s.seg >= len(s.data) {
+			return nil, fmt.Errorf("%w: record too short", errFormat)
+		}
+		if s.pos >= len(s.data[s.seg]) {
+			s.seg, s.pos = s.seg+1, 0
+			continue
+		}
+		k := min(n-len(b), len(s.data[s.seg])-s.pos)
+		b = append(b, s.data[s.seg][s.pos:s.pos+k]...)
+		s.pos += k
+	}
+	return b, nil
+}
+
+// readString reads the unicode string of cch characters, skip is the number of bytes of the
+// character count before the flags. The characters continued in the next segment are preceded
+// by new flags telling their size.
+func readString(s *segments, cch int, skip int) (string, error) {
+	if _, err := s.read(skip); err != nil {
+		return "", err
+	}
+	flags, err := s.read(1)
+	if err != nil {
+		return "", err
+	}
+	wide := flags[0]&0x01 != 0
+	runs, ext := 0, 0
+	if flags[0]&0x08 != 0 {
+		b, err := s.read(2)
+		if err != nil {
+			return "", err
+		}
+		runs = int(binary.LittleEndian.Uint16(b))
+	}
+	if flags[0]&0x04 != 0 {
+		b, err := s.read(4)
+		if err != nil {
+			return "", err
+		}
+		ext = int(binary.LittleEndian.Uint32(b))
+	}
+
+	chars := make([]uint16, 0, cch)
+	for len(chars) < cch {
+		if s.seg >= len(s.data) {
+			return "", fmt.Errorf("%w: string too short", errFormat)
+		}
+		if s.pos >= len(s.data[s.seg]) {
+			s.seg, s.pos = s.seg+1, 0
+			if s.seg >= len(s.data) || len(s.data[s.seg]) == 0 {
+				return "", fmt.Errorf("%w: string too short", errFormat)
+			}
+			wide = s.data[s.seg][0]&0x01 != 0
+			s.pos = 1
+			continue
+		}
+		seg := s.data[s.seg]
+		if wide {
+			if s.pos+2 > len(seg) {
+				return "", fmt.Errorf("%w: string too short", errFormat)
+			}
+			chars = append(chars, binary.LittleEndian.Uint16(seg[s.pos:]))
+			s.pos += 2
+		} else {
+			chars = append(chars, uint16(seg[s.pos]))
+			s.pos++
+		}
+	}
+	if _, err := s.read(runs*4 + ext); err != nil {
+		return "", err
+	}
+	return string(utf16.Decode(chars)), nil
+}
+
+// readSST reads the strings of the shared string table
+func readSST(data [][]byte) ([]string, error) {
+	if len(data[0]) < 8 {
+		return nil, fmt.Errorf("%w: bad SST record", errFormat)
+	}
+	count := int(binary.LittleEndian.Uint32(data[0][4:]))
+	s := &segments{data: data, pos: 8}
+	result := make([]string, 0, min(count, 1<<16))
+	for i := 0; i < count; i++ {
+		b, err := s.read(2)
+		if err != nil {
+			// the count of the table may be larger than the strings written
+			break
+		}
+		str, err := readString(s, int(binary.LittleEndian.Uint16(b)), 0)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, str)
+	}
+	return result, nil
+}
@@ -20,6 +20,15 @@ function pdf_password() {
   );
 }
 
+// yao run scripts.test.pdf_options
+function pdf_options() {
+  return Process(
+    "plugins.docloader.text",
+    getFilePath("sample_password.pdf"),
+    { password: "password", chunk_size: 300, chunk_overlap: 30 }
+  );
+}
+
 // yao run scripts.test.html
 function html() {
   return Process("plugins.docloader.text", getFilePath("test.html"));
@@ -60,3 +69,33 @@ function md() {
 function wiz() {
   return Process("plugins.docloader.text", getFilePath("test1.ziw"));
 }
+
+// yao run scripts.test.load
+function load() {
+  return Process("plugins.docloader.load", getFilePath("sample.pdf"));
+}
+
+// yao run scripts.test.split
+function split() {
+  const docs = Process("plugins.docloader.load", getFilePath("sample.pdf"));
+  return Process("plugins.docloader.split", docs.data, {
+    splitter: "recursive",
+    chunk_size: 300,
+  });
+}
+
+// yao run scripts.test.cursor
+function cursor() {
+  const res = Process("plugins.docloader.open", getFilePath("sample.pdf"), {
+    chunk_size: 300,
+  });
+  const docs = [];
+  while (true) {
+    const batch = Process("plugins.docloader.next", res.data.cursor, 10);
+    docs.push(...batch.data.documents);
+    if (batch.data.done) {
+      break;
+    }
+  }
+  return docs;
+}